@@ -0,0 +1,206 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AccountManager holds the credentials of many accounts which share one
+// client's consumer config, transport and rate limit tracker.  Each account
+// is used through a view of the shared client, so limits are tracked for
+// each account separately.  It is safe for concurrent use.
+type AccountManager struct {
+	client *Client
+
+	mu       sync.Mutex
+	accounts []*managedAccount
+	// Where LeastExhausted starts looking, so that ties are shared out.
+	next int
+}
+
+type managedAccount struct {
+	account *StoredAccount
+	client  *Client
+}
+
+// Creates a manager whose accounts are views of client.  Configure the
+// client's transport, retry policy and rate limit tracker before adding
+// accounts.
+func NewAccountManager(client *Client) *AccountManager {
+	return &AccountManager{client: client}
+}
+
+// Returns the client which the accounts are views of.
+func (m *AccountManager) Client() *Client {
+	return m.client
+}
+
+// Adds an account, replacing any account with the same user ID, or the same
+// screen name if the user ID is not set.  Returns the account's client.
+func (m *AccountManager) Add(account *StoredAccount) (*Client, error) {
+	if account.UserId == 0 && account.ScreenName == "" {
+		return nil, fmt.Errorf("Account needs a user ID or screen name")
+	}
+	if account.AccessToken == "" && account.UserToken == nil {
+		return nil, fmt.Errorf("Account %v has no credentials", account.key())
+	}
+	var (
+		copied = *account
+		view   = m.client.WithUser(copied.UserConfig())
+	)
+	view.UserToken = copied.UserToken
+	view.account = &copied
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// The slice is replaced rather than modified, as LeastExhausted reads
+	// it without the lock.
+	var accounts = make([]*managedAccount, 0, len(m.accounts)+1)
+	for _, a := range m.accounts {
+		if !a.account.sameAccount(&copied) {
+			accounts = append(accounts, a)
+		}
+	}
+	m.accounts = append(accounts, &managedAccount{&copied, view})
+	return view, nil
+}
+
+// Adds every account in store.
+func (m *AccountManager) Load(store TokenStore) error {
+	accounts, err := store.List()
+	if err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		if _, err = m.Add(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Removes the account whose user ID or screen name is key.
+func (m *AccountManager) Remove(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out = make([]*managedAccount, 0, len(m.accounts))
+	for _, a := range m.accounts {
+		if !a.account.Matches(key) {
+			out = append(out, a)
+		}
+	}
+	m.accounts = out
+}
+
+// Returns the client for the account whose user ID or screen name is key,
+// or ErrAccountNotFound.
+func (m *AccountManager) Account(key string) (*Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, a := range m.accounts {
+		if a.account.Matches(key) {
+			return a.client, nil
+		}
+	}
+	return nil, ErrAccountNotFound
+}
+
+// Returns the managed accounts.
+func (m *AccountManager) Accounts() []*StoredAccount {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out = make([]*StoredAccount, len(m.accounts))
+	for i, a := range m.accounts {
+		var copied = *a.account
+		out[i] = &copied
+	}
+	return out
+}
+
+// Returns how many requests remain for the endpoint at path using the
+// account whose user ID or screen name is key.  The boolean is false if no
+// limit has been recorded.
+func (m *AccountManager) RateLimitRemaining(key string, path string) (uint32, bool) {
+	c, err := m.Account(key)
+	if err != nil {
+		return 0, false
+	}
+	return c.RateLimitRemaining(path)
+}
+
+// Returns the client for the account with the most requests remaining for
+// the endpoint at path.  Accounts which have not used the endpoint are
+// assumed to have their full limit.  If every account is exhausted, the one
+// whose limit resets first is returned, and the client's rate limit
+// tracker decides what happens to its requests.  Only use this for reads,
+// which do not depend on the account making them.
+func (m *AccountManager) LeastExhausted(path string) (*Client, error) {
+	m.mu.Lock()
+	var (
+		accounts  = m.accounts
+		start     = m.next
+		best      *Client
+		bestLeft  uint32
+		bestReset time.Time
+	)
+	m.next++
+	m.mu.Unlock()
+	if len(accounts) == 0 {
+		return nil, ErrAccountNotFound
+	}
+	// The accounts are compared on a snapshot of the list, so that the
+	// client and tracker locks taken by Identity and Get are never held
+	// while holding the manager's lock.
+	for i := range accounts {
+		var (
+			c     = accounts[(start+i)%len(accounts)].client
+			left  uint32
+			reset time.Time
+		)
+		limit, ok := RateLimitError{}, false
+		if c.RateLimits != nil {
+			limit, ok = c.RateLimits.Get(c.Identity(), path)
+		}
+		if !ok {
+			// Unused, or untracked: as good as any account can be.
+			left = ^uint32(0)
+		} else if time.Now().Before(limit.Reset) {
+			left, reset = limit.Remaining, limit.Reset
+		} else {
+			left = limit.Limit
+		}
+		if best == nil || left > bestLeft || (left == 0 && bestLeft == 0 && reset.Before(bestReset)) {
+			best, bestLeft, bestReset = c, left, reset
+		}
+	}
+	return best, nil
+}
+
+// Sends a GET request using the account returned by LeastExhausted for the
+// request's path.
+func (m *AccountManager) SendReadRequest(ctx context.Context, req *http.Request) (*APIResponse, error) {
+	if req.Method != "GET" {
+		return nil, fmt.Errorf("SendReadRequest only sends GET requests, got %v", req.Method)
+	}
+	c, err := m.LeastExhausted(req.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+	return c.SendRequestContext(ctx, req)
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestAccountManager(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		remaining = map[string]int{"alice": 2, "bob": 10, "carol": 5}
+		token     = regexp.MustCompile(`oauth_token="(\w+)"`)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var name = token.FindStringSubmatch(r.Header.Get("Authorization"))[1]
+		remaining[name]--
+		w.Header().Set(H_LIMIT, "15")
+		w.Header().Set(H_LIMIT_REMAIN, fmt.Sprint(remaining[name]))
+		w.Header().Set(H_LIMIT_RESET, "2000000000")
+		fmt.Fprintf(w, `{"user":%q}`, name)
+	}))
+	defer server.Close()
+
+	var m = NewAccountManager(getTestClient(server))
+	for i, name := range []string{"alice", "bob", "carol"} {
+		if _, err := m.Add(&StoredAccount{UserId: uint64(i + 1), ScreenName: name, AccessToken: name, AccessTokenSecret: "secret"}); err != nil {
+			t.Fatalf("Add returned error: %v", err)
+		}
+	}
+	if _, err := m.Add(&StoredAccount{ScreenName: "dave"}); err == nil {
+		t.Errorf("Expected error adding account without credentials")
+	}
+
+	var send = func(c *Client) string {
+		req, _ := http.NewRequest("GET", server.URL+"/1.1/search/tweets.json?q=go", nil)
+		resp, err := c.SendRequestContext(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error sending request: %v", err)
+		}
+		var out map[string]string
+		if err = resp.Parse(&out); err != nil {
+			t.Fatalf("Unexpected error parsing response: %v", err)
+		}
+		return out["user"]
+	}
+	// Every account is used once before limits are known.
+	for _, key := range []string{"1", "@Bob", "carol"} {
+		c, err := m.Account(key)
+		if err != nil {
+			t.Fatalf("Account(%v) returned error: %v", key, err)
+		}
+		send(c)
+	}
+	if left, ok := m.RateLimitRemaining("alice", "/1.1/search/tweets.json"); !ok || left != 1 {
+		t.Errorf("Expected 1 request left for alice, got %v %v", left, ok)
+	}
+	for i := 0; i < 5; i++ {
+		c, err := m.LeastExhausted("/1.1/search/tweets.json")
+		if err != nil {
+			t.Fatalf("LeastExhausted returned error: %v", err)
+		}
+		if got := send(c); got != "bob" {
+			t.Errorf("Expected bob to have the most requests left, got %v", got)
+		}
+	}
+	// bob and carol now both have 4 left; ties alternate.
+	var picked = map[string]bool{}
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", server.URL+"/1.1/search/tweets.json?q=go", nil)
+		resp, err := m.SendReadRequest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("SendReadRequest returned error: %v", err)
+		}
+		var out map[string]string
+		resp.Parse(&out)
+		picked[out["user"]] = true
+	}
+	if !picked["bob"] || !picked["carol"] {
+		t.Errorf("Expected ties to be shared, picked %v", picked)
+	}
+
+	m.Remove("bob")
+	if _, err := m.Account("bob"); err != ErrAccountNotFound {
+		t.Errorf("Expected bob to be removed, got %v", err)
+	}
+	if len(m.Accounts()) != 2 {
+		t.Errorf("Unexpected accounts %v", m.Accounts())
+	}
+	req, _ := http.NewRequest("POST", server.URL+"/1.1/statuses/update.json", nil)
+	if _, err := m.SendReadRequest(context.Background(), req); err == nil {
+		t.Errorf("Expected error sending POST as a read")
+	}
+}
+
+func TestAccountManagerWithoutRateLimits(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	var c = getTestClient(server)
+	c.RateLimits = nil
+	var m = NewAccountManager(c)
+	for i, name := range []string{"alice", "bob"} {
+		if _, err := m.Add(&StoredAccount{UserId: uint64(i + 1), ScreenName: name, AccessToken: name, AccessTokenSecret: "secret"}); err != nil {
+			t.Fatalf("Add returned error: %v", err)
+		}
+	}
+	if c, err := m.LeastExhausted("/1.1/search/tweets.json"); err != nil || c == nil {
+		t.Fatalf("Expected an account without a rate limit tracker, got %v %v", c, err)
+	}
+	req, _ := http.NewRequest("GET", server.URL+"/1.1/search/tweets.json?q=go", nil)
+	if _, err := m.SendReadRequest(context.Background(), req); err != nil {
+		t.Fatalf("SendReadRequest returned error: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %v", requests)
+	}
+}
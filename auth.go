@@ -0,0 +1,263 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/kurrik/oauth1a"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The callback URL which tells Twitter to display a PIN instead of
+// redirecting the user.
+const CALLBACK_OOB = "oob"
+
+// How long a request token from BeginUserAuth may wait for the user to
+// return.  Twitter expires unused request tokens sooner than this.
+const PENDING_AUTH_TTL = 30 * time.Minute
+
+// Returned when the user declines to authorize the application.
+var ErrAuthDenied = errors.New("User denied authorization")
+
+// The credentials of a user who has authorized the application, along with
+// the account details returned with the access token.
+type AuthorizedUser struct {
+	*oauth1a.UserConfig
+	UserId     uint64
+	ScreenName string
+}
+
+// Request tokens issued by BeginUserAuth which have not been exchanged yet,
+// keyed by token.
+type pendingAuth struct {
+	mu     sync.Mutex
+	tokens map[string]pendingToken
+}
+
+type pendingToken struct {
+	user    *oauth1a.UserConfig
+	expires time.Time
+}
+
+func (p *pendingAuth) put(user *oauth1a.UserConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var now = time.Now()
+	if p.tokens == nil {
+		p.tokens = map[string]pendingToken{}
+	}
+	for token, pending := range p.tokens {
+		if now.After(pending.expires) {
+			delete(p.tokens, token)
+		}
+	}
+	p.tokens[user.RequestTokenKey] = pendingToken{user, now.Add(PENDING_AUTH_TTL)}
+}
+
+func (p *pendingAuth) take(token string) (user *oauth1a.UserConfig, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pending, ok := p.tokens[token]
+	delete(p.tokens, token)
+	if !ok || time.Now().After(pending.expires) {
+		return nil, false
+	}
+	return pending.user, true
+}
+
+// Returns a copy of the client's OAuth service which sends callbackURL
+// with request token requests.
+func (c *Client) userAuthService(callbackURL string) *oauth1a.Service {
+	var (
+		service = *c.OAuth
+		config  = *c.OAuth.ClientConfig
+	)
+	config.CallbackURL = callbackURL
+	service.ClientConfig = &config
+	service.Signer = clockSigner{service.Signer, c}
+	return &service
+}
+
+// Starts the three-legged OAuth flow.  Returns the URL to send the user to;
+// once they authorize the application, Twitter redirects them to
+// callbackURL with oauth_token and oauth_verifier parameters which should
+// be passed to CompleteUserAuth.
+// https://developer.twitter.com/en/docs/authentication/oauth-1-0a/obtaining-user-access-tokens
+func (c *Client) BeginUserAuth(callbackURL string) (authURL string, err error) {
+	return c.BeginUserAuthContext(context.Background(), callbackURL)
+}
+
+// BeginUserAuthContext is like BeginUserAuth, but requests the token with
+// the supplied context.
+func (c *Client) BeginUserAuthContext(ctx context.Context, callbackURL string) (authURL string, err error) {
+	_, authURL, err = c.beginUserAuth(ctx, callbackURL)
+	return
+}
+
+// Requests a request token and records it as pending.
+func (c *Client) beginUserAuth(ctx context.Context, callbackURL string) (user *oauth1a.UserConfig, authURL string, err error) {
+	user = &oauth1a.UserConfig{}
+	if err = user.GetRequestToken(ctx, c.userAuthService(callbackURL), c.HttpClient); err != nil {
+		return
+	}
+	if authURL, err = user.GetAuthorizeURL(c.OAuth); err != nil {
+		return
+	}
+	c.root().pendingAuth.put(user)
+	return
+}
+
+// Exchanges the token and verifier from the callback for the user's access
+// token.  The token must have been issued by BeginUserAuth on this client.
+// If the client has a TokenStore, the user is saved to it; should that
+// fail, the user is returned along with the error.
+func (c *Client) CompleteUserAuth(token string, verifier string) (*AuthorizedUser, error) {
+	return c.CompleteUserAuthContext(context.Background(), token, verifier)
+}
+
+// CompleteUserAuthContext is like CompleteUserAuth, but requests the access
+// token with the supplied context.
+func (c *Client) CompleteUserAuthContext(ctx context.Context, token string, verifier string) (*AuthorizedUser, error) {
+	user, ok := c.root().pendingAuth.take(token)
+	if !ok {
+		return nil, fmt.Errorf("Unknown or expired request token %v", token)
+	}
+	// oauth1a only sends the verifier when the service has a callback URL.
+	var service = c.userAuthService(CALLBACK_OOB)
+	return c.exchangeAccessToken(ctx, user, token, verifier, service)
+}
+
+// Exchanges a request token for an access token and reads the account
+// details from the response.
+func (c *Client) exchangeAccessToken(ctx context.Context, user *oauth1a.UserConfig, token string, verifier string, service *oauth1a.Service) (*AuthorizedUser, error) {
+	if err := user.GetAccessToken(ctx, token, verifier, service, c.HttpClient); err != nil {
+		return nil, err
+	}
+	var (
+		id, _ = strconv.ParseUint(user.AccessValues.Get("user_id"), 10, 64)
+		au    = &AuthorizedUser{
+			UserConfig: user,
+			UserId:     id,
+			ScreenName: user.AccessValues.Get("screen_name"),
+		}
+	)
+	return au, c.storeAuthorizedUser(au)
+}
+
+// Returns a handler for the callback URL passed to BeginUserAuth.  It
+// completes the authorization and calls done with the result, which should
+// write the response shown to the user.  If the user declined, done is
+// called with ErrAuthDenied.
+func (c *Client) UserAuthHandler(done func(w http.ResponseWriter, r *http.Request, user *AuthorizedUser, err error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("denied") != "" {
+			c.root().pendingAuth.take(r.URL.Query().Get("denied"))
+			done(w, r, nil, ErrAuthDenied)
+			return
+		}
+		var (
+			token    string
+			verifier string
+			user     *AuthorizedUser
+			err      error
+		)
+		if token, verifier, err = (&oauth1a.UserConfig{}).ParseAuthorize(r, c.OAuth); err == nil {
+			user, err = c.CompleteUserAuthContext(r.Context(), token, verifier)
+		}
+		done(w, r, user, err)
+	})
+}
+
+// Runs the whole three-legged flow for a command line tool.  Serves the
+// callback on addr, such as "127.0.0.1:8080", at the given path, and calls
+// open with the URL the user should visit.  Returns once the callback has
+// been handled or ctx is done.  The callback URL must be registered for
+// the application.
+func (c *Client) AuthorizeWithLocalServer(ctx context.Context, addr string, path string, open func(authURL string)) (user *AuthorizedUser, err error) {
+	type authResult struct {
+		user *AuthorizedUser
+		err  error
+	}
+	var (
+		listener net.Listener
+		authURL  string
+		result   = make(chan authResult, 1)
+		mux      = http.NewServeMux()
+		server   = &http.Server{Handler: mux}
+	)
+	if listener, err = net.Listen("tcp", addr); err != nil {
+		return
+	}
+	defer listener.Close()
+	defer server.Close()
+	mux.Handle(path, c.UserAuthHandler(func(w http.ResponseWriter, r *http.Request, u *AuthorizedUser, uerr error) {
+		if uerr != nil {
+			http.Error(w, uerr.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Authorized.  You may close this window.")
+		}
+		select {
+		case result <- authResult{u, uerr}:
+		default:
+		}
+	}))
+	go server.Serve(listener)
+	callback := fmt.Sprintf("http://%v%v", listener.Addr(), path)
+	if authURL, err = c.BeginUserAuthContext(ctx, callback); err != nil {
+		return
+	}
+	open(authURL)
+	select {
+	case r := <-result:
+		return r.user, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Runs the PIN-based flow for tools which cannot receive a callback.
+// Writes the URL the user should visit to out, then reads the PIN Twitter
+// displays to them from the first line of in.
+// https://developer.twitter.com/en/docs/authentication/oauth-1-0a/pin-based-oauth
+func (c *Client) AuthorizeWithPIN(ctx context.Context, out io.Writer, in io.Reader) (*AuthorizedUser, error) {
+	var (
+		request *oauth1a.UserConfig
+		authURL string
+		pin     string
+		err     error
+	)
+	if request, authURL, err = c.beginUserAuth(ctx, CALLBACK_OOB); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(out, "Visit this URL to authorize the application:\n%v\nEnter the PIN: ", authURL)
+	if pin, err = bufio.NewReader(in).ReadString('\n'); err != nil && !(err == io.EOF && pin != "") {
+		c.root().pendingAuth.take(request.RequestTokenKey)
+		return nil, fmt.Errorf("Could not read PIN: %v", err)
+	}
+	if pin = strings.TrimSpace(pin); pin == "" {
+		c.root().pendingAuth.take(request.RequestTokenKey)
+		return nil, fmt.Errorf("No PIN entered")
+	}
+	return c.CompleteUserAuthContext(ctx, request.RequestTokenKey, pin)
+}
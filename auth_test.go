@@ -0,0 +1,189 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Returns a server implementing the OAuth token endpoints, and a client
+// with no user credentials which uses it.  The most recent oauth_callback
+// is sent on callbacks if there is room.
+func getAuthTestServer(t *testing.T, callbacks chan<- string) (*httptest.Server, *Client) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var auth = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/oauth/request_token":
+			body, _ := ioutil.ReadAll(r.Body)
+			v, _ := url.ParseQuery(string(body))
+			if callback := v.Get("oauth_callback"); callback == "" {
+				t.Errorf("Request token request missing oauth_callback: %s", body)
+			} else {
+				select {
+				case callbacks <- callback:
+				default:
+				}
+			}
+			fmt.Fprint(w, "oauth_token=request&oauth_token_secret=request_secret&oauth_callback_confirmed=true")
+		case "/oauth/access_token":
+			body, _ := ioutil.ReadAll(r.Body)
+			if !strings.Contains(auth, `oauth_token="request"`) {
+				t.Errorf("Access token request not signed with request token: %v", auth)
+			}
+			if v, _ := url.ParseQuery(string(body)); v.Get("oauth_verifier") != "verifier" {
+				t.Errorf("Access token request missing verifier: %s", body)
+			}
+			fmt.Fprint(w, "oauth_token=access&oauth_token_secret=access_secret&user_id=6253282&screen_name=TwitterAPI")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	c := getTestClient(server)
+	c.User = nil
+	c.OAuth.RequestURL = server.URL + "/oauth/request_token"
+	c.OAuth.AuthorizeURL = server.URL + "/oauth/authorize"
+	c.OAuth.AccessURL = server.URL + "/oauth/access_token"
+	return server, c
+}
+
+func TestUserAuth(t *testing.T) {
+	server, c := getAuthTestServer(t, nil)
+	defer server.Close()
+
+	var (
+		authURL string
+		user    *AuthorizedUser
+		err     error
+	)
+	if authURL, err = c.BeginUserAuth("http://localhost/callback"); err != nil {
+		t.Fatalf("BeginUserAuth returned error: %v", err)
+	}
+	if authURL != server.URL+"/oauth/authorize?oauth_token=request" {
+		t.Errorf("Unexpected authorize URL %v", authURL)
+	}
+	if user, err = c.CompleteUserAuth("request", "verifier"); err != nil {
+		t.Fatalf("CompleteUserAuth returned error: %v", err)
+	}
+	if user.AccessTokenKey != "access" || user.AccessTokenSecret != "access_secret" {
+		t.Errorf("Unexpected access token %v", user.UserConfig)
+	}
+	if user.UserId != 6253282 || user.ScreenName != "TwitterAPI" {
+		t.Errorf("Unexpected account %v %v", user.UserId, user.ScreenName)
+	}
+	if _, err = c.CompleteUserAuth("request", "verifier"); err == nil {
+		t.Errorf("Expected error reusing a request token")
+	}
+}
+
+func TestUserAuthHandler(t *testing.T) {
+	server, c := getAuthTestServer(t, nil)
+	defer server.Close()
+
+	var (
+		got     *AuthorizedUser
+		goterr  error
+		handler = c.UserAuthHandler(func(w http.ResponseWriter, r *http.Request, user *AuthorizedUser, err error) {
+			got, goterr = user, err
+		})
+		rec *httptest.ResponseRecorder
+	)
+	if _, err := c.BeginUserAuth("http://localhost/callback"); err != nil {
+		t.Fatalf("BeginUserAuth returned error: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/callback?denied=request", nil))
+	if goterr != ErrAuthDenied {
+		t.Errorf("Expected ErrAuthDenied, got %v", goterr)
+	}
+
+	if _, err := c.BeginUserAuth("http://localhost/callback"); err != nil {
+		t.Fatalf("BeginUserAuth returned error: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/callback?oauth_token=request&oauth_verifier=verifier", nil))
+	if goterr != nil {
+		t.Fatalf("Handler returned error: %v", goterr)
+	}
+	if got.ScreenName != "TwitterAPI" {
+		t.Errorf("Unexpected user %v", got)
+	}
+}
+
+func TestAuthorizeWithLocalServer(t *testing.T) {
+	var callbacks = make(chan string, 1)
+	server, c := getAuthTestServer(t, callbacks)
+	defer server.Close()
+
+	var (
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		open        = func(authURL string) {
+			// Play the part of Twitter redirecting the user's browser.
+			go func() {
+				callback := <-callbacks
+				resp, err := http.Get(callback + "?oauth_token=request&oauth_verifier=verifier")
+				if err != nil {
+					t.Errorf("Callback request failed: %v", err)
+					return
+				}
+				resp.Body.Close()
+			}()
+		}
+	)
+	defer cancel()
+	user, err := c.AuthorizeWithLocalServer(ctx, "127.0.0.1:0", "/callback", open)
+	if err != nil {
+		t.Fatalf("AuthorizeWithLocalServer returned error: %v", err)
+	}
+	if user.AccessTokenKey != "access" {
+		t.Errorf("Unexpected user %v", user.UserConfig)
+	}
+}
+
+func TestAuthorizeWithPIN(t *testing.T) {
+	var callbacks = make(chan string, 1)
+	server, c := getAuthTestServer(t, callbacks)
+	defer server.Close()
+
+	var (
+		out  = &bytes.Buffer{}
+		user *AuthorizedUser
+		err  error
+	)
+	if user, err = c.AuthorizeWithPIN(context.Background(), out, strings.NewReader("verifier\n")); err != nil {
+		t.Fatalf("AuthorizeWithPIN returned error: %v", err)
+	}
+	if callback := <-callbacks; callback != CALLBACK_OOB {
+		t.Errorf("Expected oob callback, got %v", callback)
+	}
+	if !strings.Contains(out.String(), server.URL+"/oauth/authorize?oauth_token=request") {
+		t.Errorf("Authorize URL not printed: %v", out.String())
+	}
+	if user.AccessTokenKey != "access" || user.ScreenName != "TwitterAPI" {
+		t.Errorf("Unexpected user %v", user)
+	}
+
+	if _, err = c.AuthorizeWithPIN(context.Background(), out, strings.NewReader("  \n")); err == nil {
+		t.Errorf("Expected error for an empty PIN")
+	}
+}
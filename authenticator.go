@@ -0,0 +1,134 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurrik/oauth1a"
+	"net/http"
+)
+
+// Authenticator adds credentials to requests sent by a Client.
+//
+// By default a Client signs with its OAuth 1.0a User if one is set, then
+// with its OAuth 2.0 UserToken, and otherwise with the app-only bearer
+// token.  Set Client.Auth to choose an Authenticator for every request, or
+// use WithAuthenticator to choose one for a single request.
+type Authenticator interface {
+	// Adds credentials to req, which is about to be sent by c.  Called again
+	// each time a request is retried.
+	Authenticate(ctx context.Context, c *Client, req *http.Request) error
+	// Returns the key under which rate limits for requests authenticated
+	// this way are tracked.
+	Identity(c *Client) string
+}
+
+// Signs requests with OAuth 1.0a user credentials.  If User is nil, the
+// client's User is used.
+type UserAuth struct {
+	User *oauth1a.UserConfig
+}
+
+func (a UserAuth) user(c *Client) *oauth1a.UserConfig {
+	if a.User != nil {
+		return a.User
+	}
+	return c.User
+}
+
+func (a UserAuth) Authenticate(ctx context.Context, c *Client, req *http.Request) error {
+	var user = a.user(c)
+	if user == nil {
+		return fmt.Errorf("No OAuth1 user credentials")
+	}
+	c.setOAuthTimestamp(req)
+	return c.OAuth.Sign(req, user)
+}
+
+func (a UserAuth) Identity(c *Client) string {
+	if user := a.user(c); user != nil {
+		return "user:" + user.AccessTokenKey
+	}
+	return "user:"
+}
+
+// Signs requests with the client's app-only bearer token, fetching one if
+// needed.
+type AppAuth struct{}
+
+func (a AppAuth) Authenticate(ctx context.Context, c *Client, req *http.Request) error {
+	return c.SignContext(ctx, req)
+}
+
+func (a AppAuth) Identity(c *Client) string {
+	return IDENTITY_APP
+}
+
+// Signs requests with the client's OAuth 2.0 user token, refreshing it
+// shortly before it expires.
+type OAuth2UserAuth struct{}
+
+func (a OAuth2UserAuth) Authenticate(ctx context.Context, c *Client, req *http.Request) error {
+	return c.signOAuth2(ctx, req)
+}
+
+func (a OAuth2UserAuth) Identity(c *Client) string {
+	var token = c.userToken()
+	if token == nil {
+		return "oauth2:"
+	}
+	return oauth2Identity(token)
+}
+
+// Sends requests without credentials.
+type NoAuth struct{}
+
+func (a NoAuth) Authenticate(ctx context.Context, c *Client, req *http.Request) error {
+	return nil
+}
+
+func (a NoAuth) Identity(c *Client) string {
+	return IDENTITY_NONE
+}
+
+type authenticatorKey struct{}
+
+// Returns a context which makes a Client authenticate requests sent with it
+// using a, regardless of the client's own credentials.  For example, to
+// make a single request with app-only auth while a user is set:
+//
+//	ctx = twittergo.WithAuthenticator(ctx, twittergo.AppAuth{})
+//	resp, err = client.SendRequestContext(ctx, req)
+func WithAuthenticator(ctx context.Context, a Authenticator) context.Context {
+	return context.WithValue(ctx, authenticatorKey{}, a)
+}
+
+// Returns the Authenticator for a request sent with ctx.
+func (c *Client) authenticator(ctx context.Context) Authenticator {
+	if a, ok := ctx.Value(authenticatorKey{}).(Authenticator); ok && a != nil {
+		return a
+	}
+	if c.Auth != nil {
+		return c.Auth
+	}
+	switch {
+	case c.User != nil:
+		return UserAuth{}
+	case c.userToken() != nil:
+		return OAuth2UserAuth{}
+	}
+	return AppAuth{}
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurrik/oauth1a"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Sets a custom header instead of signing.
+type headerAuth string
+
+func (a headerAuth) Authenticate(ctx context.Context, c *Client, req *http.Request) error {
+	req.Header.Set("Authorization", string(a))
+	return nil
+}
+
+func (a headerAuth) Identity(c *Client) string {
+	return "header"
+}
+
+func TestAuthenticators(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	var (
+		c    = getTestClient(server)
+		send = func(ctx context.Context) string {
+			req, _ := http.NewRequest("GET", server.URL+"/1.1/search/tweets.json?q=go", nil)
+			resp, err := c.SendRequestContext(ctx, req)
+			if err != nil {
+				t.Fatalf("Unexpected error sending request: %v", err)
+			}
+			resp.Body.Close()
+			return auth
+		}
+		ctx = context.Background()
+	)
+	c.SetAppToken("apptoken")
+	c.UserToken = &OAuth2Token{AccessToken: "usertoken"}
+
+	if got := send(ctx); !strings.Contains(got, `oauth_token="token"`) {
+		t.Errorf("Expected OAuth1 user by default, got %v", got)
+	}
+	if got := send(WithAuthenticator(ctx, AppAuth{})); got != "Bearer apptoken" {
+		t.Errorf("Expected app auth override, got %v", got)
+	}
+	if got := send(WithAuthenticator(ctx, OAuth2UserAuth{})); got != "Bearer usertoken" {
+		t.Errorf("Expected OAuth2 user override, got %v", got)
+	}
+	if got := send(WithAuthenticator(ctx, NoAuth{})); got != "" {
+		t.Errorf("Expected no auth, got %v", got)
+	}
+	other := UserAuth{User: oauth1a.NewAuthorizedConfig("other", "secret")}
+	if got := send(WithAuthenticator(ctx, other)); !strings.Contains(got, `oauth_token="other"`) {
+		t.Errorf("Expected other user, got %v", got)
+	}
+
+	c.Auth = headerAuth("Custom")
+	if got := send(ctx); got != "Custom" {
+		t.Errorf("Expected client authenticator, got %v", got)
+	}
+	if c.Identity() != "header" {
+		t.Errorf("Expected identity from client authenticator, got %v", c.Identity())
+	}
+	if got := send(WithAuthenticator(ctx, AppAuth{})); got != "Bearer apptoken" {
+		t.Errorf("Expected request override to win, got %v", got)
+	}
+}
+
+func TestAuthenticatorRateLimitIdentity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(H_LIMIT, "15")
+		w.Header().Set(H_LIMIT_REMAIN, "14")
+		w.Header().Set(H_LIMIT_RESET, "2000000000")
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	var c = getTestClient(server)
+	c.SetAppToken("apptoken")
+	req, _ := http.NewRequest("GET", server.URL+"/1.1/search/tweets.json?q=go", nil)
+	resp, err := c.SendRequestContext(WithAuthenticator(context.Background(), AppAuth{}), req)
+	if err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	resp.Body.Close()
+	if _, ok := c.RateLimits.Remaining(IDENTITY_APP, "/1.1/search/tweets.json"); !ok {
+		t.Errorf("Limit not recorded for app identity")
+	}
+	if _, ok := c.RateLimitRemaining("/1.1/search/tweets.json"); ok {
+		t.Errorf("Limit recorded for user identity")
+	}
+}
+
+func TestAuthenticatorPointerRefetchesAppToken(t *testing.T) {
+	var sent []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == PATH_OAUTH2_APP_TOKEN {
+			fmt.Fprint(w, `{"token_type":"bearer","access_token":"fresh"}`)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		sent = append(sent, string(body))
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(401)
+			fmt.Fprint(w, `{"errors":[{"code":89,"message":"Invalid or expired token."}]}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	var c = getHostTestClient(server)
+	c.SetAppToken("stale")
+	req, _ := http.NewRequest("POST", "/1.1/statuses/lookup.json", strings.NewReader("id=1"))
+	resp, err := c.SendRequestContext(WithAuthenticator(context.Background(), &AppAuth{}), req)
+	if err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	if err = resp.Parse(&map[string]interface{}{}); err != nil {
+		t.Errorf("Expected the app token to be refetched, got %v", err)
+	}
+	if len(sent) != 2 || sent[1] != "id=1" {
+		t.Errorf("Request body not replayed: %q", sent)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"github.com/kurrik/oauth1a"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Returns how far the API's clock is ahead of the local clock, as measured
+// the last time a request was rejected with ERROR_TIMESTAMP_OUT_OF_BOUNDS.
+// The offset is added to the timestamps of requests signed with OAuth 1.0a,
+// including the token requests made by BeginUserAuth and AuthorizeWithPIN.
+func (c *Client) ClockOffset() time.Duration {
+	var root = c.root()
+	root.clockMu.Lock()
+	defer root.clockMu.Unlock()
+	return root.clockOffset
+}
+
+// Sets the offset added to OAuth 1.0a timestamps, for example to restore
+// one saved from ClockOffset.
+func (c *Client) SetClockOffset(offset time.Duration) {
+	var root = c.root()
+	root.clockMu.Lock()
+	defer root.clockMu.Unlock()
+	root.clockOffset = offset
+}
+
+// Sets the OAuth 1.0a timestamp of req to the API's estimated time, if the
+// clocks are known to differ.
+func (c *Client) setOAuthTimestamp(req *http.Request) {
+	if offset := c.ClockOffset(); offset != 0 {
+		var ts = time.Now().Add(offset).Unix()
+		req.Header.Set("X-OAuth-Timestamp", strconv.FormatInt(ts, 10))
+	}
+}
+
+// Applies the client's clock offset to requests signed by oauth1a itself,
+// such as those for request and access tokens.
+type clockSigner struct {
+	oauth1a.Signer
+	client *Client
+}
+
+func (s clockSigner) Sign(req *http.Request, config *oauth1a.ClientConfig, user *oauth1a.UserConfig) error {
+	s.client.setOAuthTimestamp(req)
+	return s.Signer.Sign(req, config, user)
+}
+
+// Measures the clock offset from the Date header of resp.  Returns false if
+// the response has no usable Date.
+func (c *Client) correctClock(resp *APIResponse) bool {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return false
+	}
+	// Date is truncated to the second, so assume the middle of it.
+	c.SetClockOffset(date.Add(500 * time.Millisecond).Sub(time.Now()))
+	return true
+}
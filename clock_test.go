@@ -0,0 +1,115 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClockSkewCorrection(t *testing.T) {
+	var (
+		skew      = time.Hour
+		requests  = 0
+		reject    = false
+		timestamp = regexp.MustCompile(`oauth_timestamp="(\d+)"`)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var (
+			now   = time.Now().Add(skew)
+			m     = timestamp.FindStringSubmatch(r.Header.Get("Authorization"))
+			ts, _ = strconv.ParseInt(m[1], 10, 64)
+		)
+		w.Header().Set("Date", now.UTC().Format(http.TimeFormat))
+		if d := now.Unix() - ts; reject || d > 300 || d < -300 {
+			w.WriteHeader(STATUS_UNAUTHORIZED)
+			fmt.Fprint(w, `{"errors":[{"code":135,"message":"Timestamp out of bounds."}]}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	var c = getTestClient(server)
+	req, _ := http.NewRequest("POST", server.URL+"/1.1/statuses/update.json", strings.NewReader("status=hi"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.SendRequest(req)
+	if err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	if resp.StatusCode != STATUS_OK || requests != 2 {
+		t.Errorf("Expected success after one retry, got %v after %v requests", resp.StatusCode, requests)
+	}
+	if offset := c.WithUser(c.User).ClockOffset(); offset < skew-2*time.Second || offset > skew+2*time.Second {
+		t.Errorf("Unexpected clock offset %v", offset)
+	}
+
+	// Later requests are signed with the corrected time.
+	requests = 0
+	req, _ = http.NewRequest("GET", server.URL+"/1.1/account/verify_credentials.json", nil)
+	if resp, err = c.SendRequest(req); err != nil || resp.StatusCode != STATUS_OK || requests != 1 {
+		t.Errorf("Expected corrected request to succeed, got %v %v after %v requests", resp.StatusCode, err, requests)
+	}
+
+	// Only one retry is attempted.
+	c.SetClockOffset(0)
+	skew, reject = 0, true
+	req, _ = http.NewRequest("GET", server.URL+"/1.1/account/verify_credentials.json", nil)
+	if resp, err = c.SendRequest(req); err != nil || resp.StatusCode != STATUS_UNAUTHORIZED {
+		t.Errorf("Expected 401 to be returned, got %v %v", resp.StatusCode, err)
+	}
+	if err = resp.Parse(&map[string]interface{}{}); err == nil || !err.(Errors).HasCode(ERROR_TIMESTAMP_OUT_OF_BOUNDS) {
+		t.Errorf("Expected timestamp error from Parse, got %v", err)
+	}
+}
+
+func TestClockOffsetAppliesToUserAuth(t *testing.T) {
+	var (
+		server, c  = getAuthTestServer(t, nil)
+		handler    = server.Config.Handler
+		timestamp  = regexp.MustCompile(`oauth_timestamp="(\d+)"`)
+		timestamps []int64
+	)
+	defer server.Close()
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m := timestamp.FindStringSubmatch(r.Header.Get("Authorization")); m != nil {
+			ts, _ := strconv.ParseInt(m[1], 10, 64)
+			timestamps = append(timestamps, ts)
+		}
+		handler.ServeHTTP(w, r)
+	})
+	c.SetClockOffset(time.Hour)
+	if _, err := c.BeginUserAuth("http://localhost/callback"); err != nil {
+		t.Fatalf("BeginUserAuth returned error: %v", err)
+	}
+	if _, err := c.CompleteUserAuth("request", "verifier"); err != nil {
+		t.Fatalf("CompleteUserAuth returned error: %v", err)
+	}
+	if len(timestamps) != 2 {
+		t.Fatalf("Expected two signed token requests, got %v", timestamps)
+	}
+	for _, ts := range timestamps {
+		if d := ts - time.Now().Add(time.Hour).Unix(); d > 2 || d < -2 {
+			t.Errorf("Expected token requests to use the corrected clock, off by %vs", d)
+		}
+	}
+}
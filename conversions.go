@@ -14,7 +14,11 @@
 
 package twittergo
 
-import "strconv"
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+)
 
 func arrayValue(m map[string]interface{}, key string) []interface{} {
 	v, exists := m[key]
@@ -45,6 +49,12 @@ func int32Value(m map[string]interface{}, key string) int32 {
 		switch value := v.(type) {
 		case int32:
 			return value
+		case json.Number:
+			i, err := value.Int64()
+			if err != nil || i < math.MinInt32 || i > math.MaxInt32 {
+				return -1
+			}
+			return int32(i)
 		case int64:
 			return -1 // TODO: Should allow lib to control preference here.
 		case float64:
@@ -63,6 +73,12 @@ func int64Value(m map[string]interface{}, key string) int64 {
 		switch value := v.(type) {
 		case int64:
 			return value
+		case json.Number:
+			if i, err := value.Int64(); err == nil {
+				return i
+			}
+			f, _ := value.Float64()
+			return int64(f)
 		case float64:
 			return int64(value) // TODO: Should allow lib to control preference here.
 		default:
@@ -79,6 +95,9 @@ func float64Value(m map[string]interface{}, key string) float64 {
 		switch value := v.(type) {
 		case float64:
 			return value
+		case json.Number:
+			f, _ := value.Float64()
+			return f
 		case int64:
 			return float64(value) // TODO: Should allow lib to control preference here.
 		default:
@@ -104,6 +123,8 @@ func stringValue(m map[string]interface{}, key string) string {
 		switch value := v.(type) {
 		case string:
 			return value
+		case json.Number:
+			return value.String()
 		case int64:
 			return strconv.FormatInt(value, 10)
 		case float64:
@@ -117,3 +138,22 @@ func stringValue(m map[string]interface{}, key string) string {
 		return ""
 	}
 }
+
+// Converts an ID, which may have been sent as a number or, when
+// stringify_ids is set, as a string.
+func idValue(v interface{}) uint64 {
+	switch value := v.(type) {
+	case string:
+		id, _ := strconv.ParseUint(value, 10, 64)
+		return id
+	case json.Number:
+		id, _ := strconv.ParseUint(value.String(), 10, 64)
+		return id
+	case int64:
+		return uint64(value)
+	case float64:
+		return uint64(value) // TODO: Should allow lib to control preference here.
+	default:
+		return 0
+	}
+}
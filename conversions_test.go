@@ -15,6 +15,7 @@
 package twittergo
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -89,3 +90,37 @@ func TestConversions(t *testing.T) {
 		t.Errorf("stringValue did not product correct result for a bool key")
 	}
 }
+
+func TestNumberConversions(t *testing.T) {
+	m := map[string]interface{}{
+		"int32Key":   json.Number("1234"),
+		"int64Key":   json.Number("1002011200236892166"),
+		"float64Key": json.Number("1002011200236892166.1234"),
+		"idKey":      json.Number("18446744073709551615"),
+	}
+	if int32Value(m, "int32Key") != 1234 {
+		t.Errorf("int32Value did not produce correct result for a json.Number key")
+	}
+	if int32Value(m, "int64Key") != -1 {
+		t.Errorf("int32Value did not produce correct result for an out of range json.Number key")
+	}
+	// Unlike the float64 case above, the value is exact.
+	if int64Value(m, "int64Key") != 1002011200236892166 {
+		t.Errorf("int64Value did not produce correct result for a json.Number key")
+	}
+	if int64Value(m, "float64Key") != 1002011200236892160 {
+		t.Errorf("int64Value did not produce correct result for a fractional json.Number key")
+	}
+	if float64Value(m, "float64Key") != 1002011200236892166.1234 {
+		t.Errorf("float64Value did not produce correct result for a json.Number key")
+	}
+	if stringValue(m, "int64Key") != "1002011200236892166" {
+		t.Errorf("stringValue did not produce correct result for a json.Number key")
+	}
+	if stringValue(m, "float64Key") != "1002011200236892166.1234" {
+		t.Errorf("stringValue did not produce correct result for a fractional json.Number key")
+	}
+	if idValue(m["idKey"]) != 18446744073709551615 {
+		t.Errorf("idValue did not produce correct result for a json.Number value")
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/kurrik/oauth1a"
+	"io"
+	"os"
+	"strings"
+)
+
+// Names of the credential fields, as used in key=value files.  Environment
+// variables use the upper case name prefixed with TWITTER_, for example
+// TWITTER_CONSUMER_KEY.
+const (
+	CRED_CONSUMER_KEY        = "consumer_key"
+	CRED_CONSUMER_SECRET     = "consumer_secret"
+	CRED_ACCESS_TOKEN        = "access_token"
+	CRED_ACCESS_TOKEN_SECRET = "access_token_secret"
+	CRED_BEARER_TOKEN        = "bearer_token"
+)
+
+// Error returned when a required credential is missing or malformed.
+type CredentialsError struct {
+	Source  string
+	Field   string
+	Message string
+}
+
+func (e CredentialsError) Error() string {
+	return fmt.Sprintf("Invalid credentials in %v: %v %v", e.Source, e.Field, e.Message)
+}
+
+// Application and user credentials loaded from a file or the environment.
+// The access token and bearer token are optional.
+type Credentials struct {
+	ConsumerKey       string
+	ConsumerSecret    string
+	AccessToken       string
+	AccessTokenSecret string
+	BearerToken       string
+}
+
+// Returns an error if the consumer key or secret is missing, or if only
+// one half of the access token is present.
+func (c *Credentials) Validate(source string) error {
+	switch {
+	case c.ConsumerKey == "":
+		return CredentialsError{source, CRED_CONSUMER_KEY, "is required"}
+	case c.ConsumerSecret == "":
+		return CredentialsError{source, CRED_CONSUMER_SECRET, "is required"}
+	case c.AccessToken == "" && c.AccessTokenSecret != "":
+		return CredentialsError{source, CRED_ACCESS_TOKEN, "is required with access_token_secret"}
+	case c.AccessToken != "" && c.AccessTokenSecret == "":
+		return CredentialsError{source, CRED_ACCESS_TOKEN_SECRET, "is required with access_token"}
+	}
+	return nil
+}
+
+// Returns configs for NewClient.  The user config is nil if there is no
+// access token, in which case the client uses app-only auth.
+func (c *Credentials) Configs() (config *oauth1a.ClientConfig, user *oauth1a.UserConfig) {
+	config = &oauth1a.ClientConfig{
+		ConsumerKey:    c.ConsumerKey,
+		ConsumerSecret: c.ConsumerSecret,
+	}
+	if c.AccessToken != "" {
+		user = oauth1a.NewAuthorizedConfig(c.AccessToken, c.AccessTokenSecret)
+	}
+	return
+}
+
+// Creates a client using the credentials, including the bearer token if
+// one was supplied.
+func (c *Credentials) NewClient() *Client {
+	var client = NewClient(c.Configs())
+	if c.BearerToken != "" {
+		client.SetAppToken(c.BearerToken)
+	}
+	return client
+}
+
+// Sets the named field, returning false if the name is unknown.
+func (c *Credentials) set(name string, value string) bool {
+	switch name {
+	case CRED_CONSUMER_KEY:
+		c.ConsumerKey = value
+	case CRED_CONSUMER_SECRET:
+		c.ConsumerSecret = value
+	case CRED_ACCESS_TOKEN:
+		c.AccessToken = value
+	case CRED_ACCESS_TOKEN_SECRET:
+		c.AccessTokenSecret = value
+	case CRED_BEARER_TOKEN:
+		c.BearerToken = value
+	default:
+		return false
+	}
+	return true
+}
+
+// Reads the line based CREDENTIALS format used by the examples: the
+// consumer key, consumer secret, access token and access token secret on
+// the first four lines, and optionally a bearer token on the fifth.
+func ReadCredentials(r io.Reader, source string) (creds *Credentials, err error) {
+	var (
+		scanner = bufio.NewScanner(r)
+		fields  = []string{}
+	)
+	for scanner.Scan() {
+		fields = append(fields, strings.TrimSpace(scanner.Text()))
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	for len(fields) > 0 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) > 5 {
+		return nil, CredentialsError{source, "file", "has more than 5 lines"}
+	}
+	creds = &Credentials{}
+	for i, name := range []string{
+		CRED_CONSUMER_KEY,
+		CRED_CONSUMER_SECRET,
+		CRED_ACCESS_TOKEN,
+		CRED_ACCESS_TOKEN_SECRET,
+		CRED_BEARER_TOKEN,
+	} {
+		if i < len(fields) {
+			creds.set(name, fields[i])
+		}
+	}
+	if err = creds.Validate(source); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Reads credentials from key=value lines, such as
+//
+//	consumer_key=xxxx
+//	consumer_secret=xxxx
+//
+// Blank lines and lines starting with # are ignored.
+func ReadKeyValueCredentials(r io.Reader, source string) (creds *Credentials, err error) {
+	var (
+		scanner = bufio.NewScanner(r)
+		line    = 0
+	)
+	creds = &Credentials{}
+	for scanner.Scan() {
+		line++
+		var text = strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var parts = strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return nil, CredentialsError{source, fmt.Sprintf("line %v", line), "is not a key=value pair"}
+		}
+		var name = strings.ToLower(strings.TrimSpace(parts[0]))
+		if !creds.set(name, strings.TrimSpace(parts[1])) {
+			return nil, CredentialsError{source, name, "is not a known credential"}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err = creds.Validate(source); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Reads credentials from the TWITTER_CONSUMER_KEY, TWITTER_CONSUMER_SECRET,
+// TWITTER_ACCESS_TOKEN, TWITTER_ACCESS_TOKEN_SECRET and
+// TWITTER_BEARER_TOKEN environment variables.
+func EnvCredentials() (creds *Credentials, err error) {
+	creds = &Credentials{}
+	for _, name := range []string{
+		CRED_CONSUMER_KEY,
+		CRED_CONSUMER_SECRET,
+		CRED_ACCESS_TOKEN,
+		CRED_ACCESS_TOKEN_SECRET,
+		CRED_BEARER_TOKEN,
+	} {
+		creds.set(name, os.Getenv("TWITTER_"+strings.ToUpper(name)))
+	}
+	if err = creds.Validate("environment"); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func readCredentialsFile(path string, read func(io.Reader, string) (*Credentials, error)) (config *oauth1a.ClientConfig, user *oauth1a.UserConfig, err error) {
+	var (
+		f     *os.File
+		creds *Credentials
+	)
+	if f, err = os.Open(path); err != nil {
+		return
+	}
+	defer f.Close()
+	if creds, err = read(f, path); err != nil {
+		return
+	}
+	config, user = creds.Configs()
+	return
+}
+
+// Loads configs for NewClient from a line based CREDENTIALS file.  See
+// ReadCredentials.  Any bearer token in the file is ignored; to use it,
+// call ReadCredentials and Credentials.NewClient instead.
+func LoadCredentials(path string) (*oauth1a.ClientConfig, *oauth1a.UserConfig, error) {
+	return readCredentialsFile(path, ReadCredentials)
+}
+
+// Loads configs for NewClient from a key=value file.  See
+// ReadKeyValueCredentials.  Like LoadCredentials, it ignores bearer_token.
+func LoadKeyValueCredentials(path string) (*oauth1a.ClientConfig, *oauth1a.UserConfig, error) {
+	return readCredentialsFile(path, ReadKeyValueCredentials)
+}
+
+// Loads configs for NewClient from TWITTER_* environment variables.  See
+// EnvCredentials, whose result also carries TWITTER_BEARER_TOKEN, which
+// this ignores.
+func LoadEnvCredentials() (config *oauth1a.ClientConfig, user *oauth1a.UserConfig, err error) {
+	var creds *Credentials
+	if creds, err = EnvCredentials(); err != nil {
+		return
+	}
+	config, user = creds.Configs()
+	return
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadCredentials(t *testing.T) {
+	creds, err := ReadCredentials(strings.NewReader("key\r\nsecret\ntoken\ntoken_secret\nbearer\n\n"), "CREDENTIALS")
+	if err != nil {
+		t.Fatalf("ReadCredentials returned error: %v", err)
+	}
+	config, user := creds.Configs()
+	if config.ConsumerKey != "key" || config.ConsumerSecret != "secret" {
+		t.Errorf("Unexpected client config %v", config)
+	}
+	if user.AccessTokenKey != "token" || user.AccessTokenSecret != "token_secret" {
+		t.Errorf("Unexpected user config %v", user)
+	}
+	if creds.BearerToken != "bearer" || creds.NewClient().GetAppToken() != "bearer" {
+		t.Errorf("Unexpected bearer token %v", creds.BearerToken)
+	}
+
+	if creds, err = ReadCredentials(strings.NewReader("key\nsecret\n"), "CREDENTIALS"); err != nil {
+		t.Fatalf("ReadCredentials returned error for app-only credentials: %v", err)
+	}
+	if _, user = creds.Configs(); user != nil {
+		t.Errorf("Expected no user config, got %v", user)
+	}
+
+	var cases = map[string]string{
+		"key\n":                 CRED_CONSUMER_SECRET,
+		"key\nsecret\ntoken\n":  CRED_ACCESS_TOKEN_SECRET,
+		"\nsecret\ntoken\nts\n": CRED_CONSUMER_KEY,
+	}
+	for body, field := range cases {
+		_, err = ReadCredentials(strings.NewReader(body), "CREDENTIALS")
+		if cerr, ok := err.(CredentialsError); !ok || cerr.Field != field {
+			t.Errorf("Expected error for missing %v, got %v", field, err)
+		}
+	}
+}
+
+func TestReadKeyValueCredentials(t *testing.T) {
+	var body = `
+# Twitter credentials
+consumer_key = key
+CONSUMER_SECRET=secret=with=equals
+access_token=token
+access_token_secret=token_secret
+`
+	creds, err := ReadKeyValueCredentials(strings.NewReader(body), "twitter.conf")
+	if err != nil {
+		t.Fatalf("ReadKeyValueCredentials returned error: %v", err)
+	}
+	if creds.ConsumerKey != "key" || creds.ConsumerSecret != "secret=with=equals" || creds.AccessTokenSecret != "token_secret" {
+		t.Errorf("Unexpected credentials %v", creds)
+	}
+	if _, err = ReadKeyValueCredentials(strings.NewReader("consumer_key=key\nconsumer_secret=s\nbogus=1\n"), "twitter.conf"); err == nil {
+		t.Errorf("Expected error for unknown key")
+	}
+	if _, err = ReadKeyValueCredentials(strings.NewReader("consumer_key\n"), "twitter.conf"); err == nil {
+		t.Errorf("Expected error for malformed line")
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		path = filepath.Join(dir, "CREDENTIALS")
+	)
+	if err := ioutil.WriteFile(path, []byte("key\nsecret\ntoken\ntoken_secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config, user, err := LoadCredentials(path)
+	if err != nil {
+		t.Fatalf("LoadCredentials returned error: %v", err)
+	}
+	if config.ConsumerKey != "key" || user.AccessTokenKey != "token" {
+		t.Errorf("Unexpected configs %v %v", config, user)
+	}
+	if _, _, err = LoadCredentials(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("Expected not exist error, got %v", err)
+	}
+}
+
+func TestLoadEnvCredentials(t *testing.T) {
+	for _, name := range []string{"CONSUMER_KEY", "CONSUMER_SECRET", "ACCESS_TOKEN", "ACCESS_TOKEN_SECRET", "BEARER_TOKEN"} {
+		defer os.Setenv("TWITTER_"+name, os.Getenv("TWITTER_"+name))
+		os.Unsetenv("TWITTER_" + name)
+	}
+	os.Setenv("TWITTER_CONSUMER_KEY", "key")
+	if _, _, err := LoadEnvCredentials(); err == nil || !strings.Contains(err.Error(), CRED_CONSUMER_SECRET) {
+		t.Errorf("Expected error naming consumer_secret, got %v", err)
+	}
+	os.Setenv("TWITTER_CONSUMER_SECRET", "secret")
+	config, user, err := LoadEnvCredentials()
+	if err != nil {
+		t.Fatalf("LoadEnvCredentials returned error: %v", err)
+	}
+	if config.ConsumerSecret != "secret" || user != nil {
+		t.Errorf("Unexpected configs %v %v", config, user)
+	}
+}
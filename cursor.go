@@ -0,0 +1,145 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"io"
+	"net/url"
+)
+
+const (
+	PATH_FOLLOWERS_IDS       = "/1.1/followers/ids.json"
+	PATH_FOLLOWERS_LIST      = "/1.1/followers/list.json"
+	PATH_FRIENDS_IDS         = "/1.1/friends/ids.json"
+	PATH_FRIENDS_LIST        = "/1.1/friends/list.json"
+	PATH_LISTS_MEMBERS       = "/1.1/lists/members.json"
+	PATH_LISTS_MEMBERSHIPS   = "/1.1/lists/memberships.json"
+	PATH_LISTS_OWNERSHIPS    = "/1.1/lists/ownerships.json"
+	PATH_LISTS_SUBSCRIBERS   = "/1.1/lists/subscribers.json"
+	PATH_LISTS_SUBSCRIPTIONS = "/1.1/lists/subscriptions.json"
+)
+
+const (
+	CURSOR_START = "-1"
+	CURSOR_END   = "0"
+)
+
+// Cursored is implemented by responses which are paged with cursors, such
+// as CursoredIDs, CursoredUsers and CursoredLists.
+type Cursored interface {
+	NextCursorStr() string
+}
+
+// CursorIterator walks through every page of a cursored endpoint, setting
+// the cursor parameter of each request from the previous page's
+// next_cursor_str until the API returns a cursor of 0.
+// https://developer.twitter.com/en/docs/twitter-api/v1/pagination
+type CursorIterator struct {
+	Client *Client
+	Path   string
+	Params url.Values
+	// If set, pages which are rate limited are requested again once the
+	// limit resets instead of returning a RateLimitError.  Attempts and
+	// waiting are bounded by the client's RetryPolicy or its defaults.
+	WaitOnRateLimit bool
+
+	cursor string
+}
+
+// Creates an iterator for the endpoint at path.  Params are sent with every
+// request, along with the cursor.
+func NewCursorIterator(c *Client, path string, params url.Values) *CursorIterator {
+	return &CursorIterator{
+		Client: c,
+		Path:   path,
+		Params: params,
+		cursor: CURSOR_START,
+	}
+}
+
+// Returns true once the last page has been read.
+func (it *CursorIterator) Done() bool {
+	return it.cursor == CURSOR_END || it.cursor == ""
+}
+
+// Requests the next page and parses it into page, which should be a pointer
+// to a type such as CursoredIDs.  Returns io.EOF if there are no more pages.
+func (it *CursorIterator) Next(ctx context.Context, page Cursored) (err error) {
+	if it.Done() {
+		return io.EOF
+	}
+	var params = url.Values{}
+	for k, v := range it.Params {
+		params[k] = v
+	}
+	params.Set("cursor", it.cursor)
+	if err = it.Client.callWaiting(ctx, it.WaitOnRateLimit, "GET", it.Path, params, page); err != nil {
+		return
+	}
+	it.cursor = page.NextCursorStr()
+	return
+}
+
+// Calls fn with each ID from every remaining page of a CursoredIDs
+// endpoint, stopping early if fn returns an error.
+func (it *CursorIterator) EachID(ctx context.Context, fn func(id uint64) error) (err error) {
+	for !it.Done() {
+		var page = CursoredIDs{}
+		if err = it.Next(ctx, &page); err != nil {
+			return
+		}
+		for _, id := range page.IDs() {
+			if err = fn(id); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// Calls fn with each User from every remaining page of a CursoredUsers
+// endpoint, stopping early if fn returns an error.
+func (it *CursorIterator) EachUser(ctx context.Context, fn func(user User) error) (err error) {
+	for !it.Done() {
+		var page = CursoredUsers{}
+		if err = it.Next(ctx, &page); err != nil {
+			return
+		}
+		for _, user := range page.Users() {
+			if err = fn(user); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// Calls fn with each List from every remaining page of a CursoredLists
+// endpoint, stopping early if fn returns an error.
+func (it *CursorIterator) EachList(ctx context.Context, fn func(list List) error) (err error) {
+	for !it.Done() {
+		var page = CursoredLists{}
+		if err = it.Next(ctx, &page); err != nil {
+			return
+		}
+		for _, list := range page.Lists() {
+			if err = fn(list); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
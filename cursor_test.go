@@ -0,0 +1,167 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// Returns a server with three pages of follower IDs.  The first request for
+// each of the `limited` cursors is rate limited.
+func getCursorServer(t *testing.T, limited map[string]bool, cursors *[]string) *httptest.Server {
+	var pages = map[string]string{
+		"-1":  `{"ids":[1,2],"next_cursor_str":"100","previous_cursor_str":"0"}`,
+		"100": `{"ids":[3,4],"next_cursor_str":"200","previous_cursor_str":"-100"}`,
+		"200": `{"ids":["5"],"next_cursor_str":"0","previous_cursor_str":"-200"}`,
+	}
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var cursor = r.URL.Query().Get("cursor")
+		*cursors = append(*cursors, cursor)
+		if r.URL.Query().Get("screen_name") != "kurrik" {
+			t.Errorf("Expected parameters to be sent with each page")
+		}
+		if limited[cursor] {
+			delete(limited, cursor)
+			w.Header().Set(H_LIMIT, "15")
+			w.Header().Set(H_LIMIT_REMAIN, "0")
+			w.Header().Set(H_LIMIT_RESET, fmt.Sprintf("%v", time.Now().Add(-time.Second).Unix()))
+			w.WriteHeader(STATUS_LIMIT)
+			return
+		}
+		fmt.Fprint(w, pages[cursor])
+	}))
+}
+
+func TestCursorIteratorEachID(t *testing.T) {
+	var (
+		cursors []string
+		server  = getCursorServer(t, nil, &cursors)
+		c       = getHostTestClient(server)
+		params  = url.Values{"screen_name": []string{"kurrik"}}
+		it      = NewCursorIterator(c, PATH_FOLLOWERS_IDS, params)
+		ids     []uint64
+		err     error
+	)
+	defer server.Close()
+	err = it.EachID(context.Background(), func(id uint64) error {
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(ids) != "[1 2 3 4 5]" {
+		t.Errorf("Unexpected IDs %v", ids)
+	}
+	if fmt.Sprint(cursors) != "[-1 100 200]" {
+		t.Errorf("Unexpected cursors %v", cursors)
+	}
+	if !it.Done() {
+		t.Errorf("Expected iterator to be done")
+	}
+	if err = it.Next(context.Background(), &CursoredIDs{}); err != io.EOF {
+		t.Errorf("Expected io.EOF after the last page, got %v", err)
+	}
+	if params.Get("cursor") != "" {
+		t.Errorf("Iterator should not modify the supplied parameters")
+	}
+}
+
+func TestCursorIteratorRateLimited(t *testing.T) {
+	var (
+		cursors []string
+		server  = getCursorServer(t, map[string]bool{"100": true}, &cursors)
+		c       = getHostTestClient(server)
+		params  = url.Values{"screen_name": []string{"kurrik"}}
+		it      = NewCursorIterator(c, PATH_FOLLOWERS_IDS, params)
+		err     error
+		ok      bool
+	)
+	defer server.Close()
+	if err = it.Next(context.Background(), &CursoredIDs{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok = it.Next(context.Background(), &CursoredIDs{}).(RateLimitError); !ok {
+		t.Fatalf("Expected a RateLimitError without WaitOnRateLimit")
+	}
+}
+
+func TestCursorIteratorWaitOnRateLimit(t *testing.T) {
+	var (
+		cursors []string
+		server  = getCursorServer(t, map[string]bool{"100": true}, &cursors)
+		c       = getHostTestClient(server)
+		params  = url.Values{"screen_name": []string{"kurrik"}}
+		it      = NewCursorIterator(c, PATH_FOLLOWERS_IDS, params)
+		err     error
+	)
+	defer server.Close()
+	it.WaitOnRateLimit = true
+	err = it.EachID(context.Background(), func(id uint64) error { return nil })
+	if err != nil {
+		t.Fatalf("Expected rate limit to be waited out, got %v", err)
+	}
+	if fmt.Sprint(cursors) != "[-1 100 100 200]" {
+		t.Errorf("Expected the limited page to be requested again, got %v", cursors)
+	}
+}
+
+func TestCursorIteratorWaitOnRateLimitGivesUp(t *testing.T) {
+	var (
+		requests int
+		server   = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set(H_LIMIT_RESET, fmt.Sprintf("%v", time.Now().Add(-time.Second).Unix()))
+			w.WriteHeader(STATUS_LIMIT)
+		}))
+		c     = getHostTestClient(server)
+		it    = NewCursorIterator(c, PATH_FOLLOWERS_IDS, nil)
+		start = time.Now()
+		err   error
+		ok    bool
+	)
+	defer server.Close()
+	// SendRequest would retry these too, since the reset time is known, but
+	// the policy's attempts are only spent once.
+	c.Retry = &RetryPolicy{MaxAttempts: 3, MinWait: 20 * time.Millisecond}
+	it.WaitOnRateLimit = true
+	err = it.Next(context.Background(), &CursoredIDs{})
+	if _, ok = err.(RateLimitError); !ok {
+		t.Fatalf("Expected a RateLimitError once attempts were exhausted, got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %v", requests)
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("Expected a backed off wait between attempts, took %v", elapsed)
+	}
+}
+
+func TestCursoredUsers(t *testing.T) {
+	var users = CursoredUsers{
+		"users":           []interface{}{map[string]interface{}{"screen_name": "kurrik"}},
+		"next_cursor_str": "0",
+	}
+	if len(users.Users()) != 1 || users.Users()[0].ScreenName() != "kurrik" {
+		t.Errorf("Users not parsed correctly: %v", users.Users())
+	}
+}
@@ -0,0 +1,390 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PATH_HOME_TIMELINE     = "/1.1/statuses/home_timeline.json"
+	PATH_MENTIONS_TIMELINE = "/1.1/statuses/mentions_timeline.json"
+	PATH_USER_TIMELINE     = "/1.1/statuses/user_timeline.json"
+	PATH_UPDATE_STATUS     = "/1.1/statuses/update.json"
+	PATH_SEARCH_TWEETS     = "/1.1/search/tweets.json"
+	PATH_SHOW_USER         = "/1.1/users/show.json"
+)
+
+// Error returned when the parameters for an endpoint fail validation.
+// The request is not sent.
+type ParamError struct {
+	Param   string
+	Message string
+}
+
+func (e ParamError) Error() string {
+	return fmt.Sprintf("Invalid parameter %v: %v", e.Param, e.Message)
+}
+
+func setString(v url.Values, key string, val string) {
+	if val != "" {
+		v.Set(key, val)
+	}
+}
+
+func setInt(v url.Values, key string, val int) {
+	if val != 0 {
+		v.Set(key, strconv.Itoa(val))
+	}
+}
+
+func setId(v url.Values, key string, val uint64) {
+	if val != 0 {
+		v.Set(key, strconv.FormatUint(val, 10))
+	}
+}
+
+func setBool(v url.Values, key string, val bool) {
+	if val {
+		v.Set(key, "true")
+	}
+}
+
+func validateCount(count int, max int) error {
+	if count < 0 || count > max {
+		return ParamError{"count", fmt.Sprintf("must be between 0 and %v", max)}
+	}
+	return nil
+}
+
+func validateTweetMode(mode string) error {
+	switch mode {
+	case "", "compat", "extended":
+		return nil
+	}
+	return ParamError{"tweet_mode", "must be compat or extended"}
+}
+
+// Parameters for the statuses/user_timeline endpoint.
+// One of UserId or ScreenName is required.
+// https://developer.twitter.com/en/docs/twitter-api/v1/tweets/timelines/api-reference/get-statuses-user_timeline
+type UserTimelineParams struct {
+	UserId          uint64
+	ScreenName      string
+	SinceId         uint64
+	MaxId           uint64
+	Count           int
+	TrimUser        bool
+	ExcludeReplies  bool
+	ExcludeRetweets bool
+	TweetMode       string
+}
+
+func (p UserTimelineParams) Validate() error {
+	if p.UserId == 0 && p.ScreenName == "" {
+		return ParamError{"user_id", "one of user_id or screen_name is required"}
+	}
+	if err := validateCount(p.Count, 200); err != nil {
+		return err
+	}
+	return validateTweetMode(p.TweetMode)
+}
+
+func (p UserTimelineParams) Values() url.Values {
+	v := url.Values{}
+	setId(v, "user_id", p.UserId)
+	setString(v, "screen_name", p.ScreenName)
+	setId(v, "since_id", p.SinceId)
+	setId(v, "max_id", p.MaxId)
+	setInt(v, "count", p.Count)
+	setBool(v, "trim_user", p.TrimUser)
+	setBool(v, "exclude_replies", p.ExcludeReplies)
+	if p.ExcludeRetweets {
+		v.Set("include_rts", "false")
+	}
+	setString(v, "tweet_mode", p.TweetMode)
+	return v
+}
+
+// Parameters for the statuses/home_timeline and statuses/mentions_timeline
+// endpoints, which return Tweets for the authenticating user.
+type TimelineParams struct {
+	SinceId        uint64
+	MaxId          uint64
+	Count          int
+	TrimUser       bool
+	ExcludeReplies bool
+	TweetMode      string
+}
+
+func (p TimelineParams) Validate() error {
+	if err := validateCount(p.Count, 200); err != nil {
+		return err
+	}
+	return validateTweetMode(p.TweetMode)
+}
+
+func (p TimelineParams) Values() url.Values {
+	v := url.Values{}
+	setId(v, "since_id", p.SinceId)
+	setId(v, "max_id", p.MaxId)
+	setInt(v, "count", p.Count)
+	setBool(v, "trim_user", p.TrimUser)
+	setBool(v, "exclude_replies", p.ExcludeReplies)
+	setString(v, "tweet_mode", p.TweetMode)
+	return v
+}
+
+// Parameters for the search/tweets endpoint.  Query is required.
+// https://developer.twitter.com/en/docs/twitter-api/v1/tweets/search/api-reference/get-search-tweets
+type SearchParams struct {
+	Query      string
+	Geocode    string
+	Lang       string
+	Locale     string
+	ResultType string
+	Count      int
+	Until      string
+	SinceId    uint64
+	MaxId      uint64
+	TweetMode  string
+}
+
+func (p SearchParams) Validate() error {
+	if p.Query == "" {
+		return ParamError{"q", "is required"}
+	}
+	if len(p.Query) > 500 {
+		return ParamError{"q", "must be 500 characters or fewer"}
+	}
+	switch p.ResultType {
+	case "", "mixed", "recent", "popular":
+	default:
+		return ParamError{"result_type", "must be mixed, recent or popular"}
+	}
+	if err := validateCount(p.Count, 100); err != nil {
+		return err
+	}
+	return validateTweetMode(p.TweetMode)
+}
+
+func (p SearchParams) Values() url.Values {
+	v := url.Values{}
+	setString(v, "q", p.Query)
+	setString(v, "geocode", p.Geocode)
+	setString(v, "lang", p.Lang)
+	setString(v, "locale", p.Locale)
+	setString(v, "result_type", p.ResultType)
+	setInt(v, "count", p.Count)
+	setString(v, "until", p.Until)
+	setId(v, "since_id", p.SinceId)
+	setId(v, "max_id", p.MaxId)
+	setString(v, "tweet_mode", p.TweetMode)
+	return v
+}
+
+// Parameters for the users/show endpoint.
+// Exactly one of UserId or ScreenName is required.
+type ShowUserParams struct {
+	UserId          uint64
+	ScreenName      string
+	IncludeEntities bool
+}
+
+func (p ShowUserParams) Validate() error {
+	if (p.UserId == 0) == (p.ScreenName == "") {
+		return ParamError{"user_id", "exactly one of user_id or screen_name is required"}
+	}
+	return nil
+}
+
+func (p ShowUserParams) Values() url.Values {
+	v := url.Values{}
+	setId(v, "user_id", p.UserId)
+	setString(v, "screen_name", p.ScreenName)
+	setBool(v, "include_entities", p.IncludeEntities)
+	return v
+}
+
+// Parameters for the statuses/update endpoint.  Status is required unless
+// media or an attachment URL is supplied.
+// https://developer.twitter.com/en/docs/twitter-api/v1/tweets/post-and-engage/api-reference/post-statuses-update
+type UpdateStatusParams struct {
+	Status                    string
+	InReplyToStatusId         uint64
+	AutoPopulateReplyMetadata bool
+	AttachmentURL             string
+	MediaIds                  []string
+	PossiblySensitive         bool
+	PlaceId                   string
+	TrimUser                  bool
+	TweetMode                 string
+}
+
+func (p UpdateStatusParams) Validate() error {
+	if p.Status == "" && len(p.MediaIds) == 0 && p.AttachmentURL == "" {
+		return ParamError{"status", "is required without media_ids or attachment_url"}
+	}
+	if len(p.MediaIds) > 4 {
+		return ParamError{"media_ids", "at most 4 media ids may be attached"}
+	}
+	return validateTweetMode(p.TweetMode)
+}
+
+func (p UpdateStatusParams) Values() url.Values {
+	v := url.Values{}
+	setString(v, "status", p.Status)
+	setId(v, "in_reply_to_status_id", p.InReplyToStatusId)
+	setBool(v, "auto_populate_reply_metadata", p.AutoPopulateReplyMetadata)
+	setString(v, "attachment_url", p.AttachmentURL)
+	setString(v, "media_ids", strings.Join(p.MediaIds, ","))
+	setBool(v, "possibly_sensitive", p.PossiblySensitive)
+	setString(v, "place_id", p.PlaceId)
+	setBool(v, "trim_user", p.TrimUser)
+	setString(v, "tweet_mode", p.TweetMode)
+	return v
+}
+
+// Creates a request for the endpoint at u with the supplied parameters.
+// GET parameters are sent in the query string, others in a form body.
+func newParamsRequest(ctx context.Context, method string, u string, params url.Values) (req *http.Request, err error) {
+	var body io.Reader
+	if method == "GET" {
+		if len(params) > 0 {
+			u = u + "?" + params.Encode()
+		}
+	} else {
+		body = strings.NewReader(params.Encode())
+	}
+	if req, err = http.NewRequestWithContext(ctx, method, u, body); err != nil {
+		return
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return
+}
+
+// Sends a request to the endpoint at path and parses the response into out.
+func (c *Client) call(ctx context.Context, method string, path string, params url.Values, out interface{}) (err error) {
+	var (
+		req  *http.Request
+		resp *APIResponse
+	)
+	if req, err = newParamsRequest(ctx, method, path, params); err != nil {
+		return
+	}
+	if resp, err = c.SendRequestContext(ctx, req); err != nil {
+		return
+	}
+	return resp.ParseContext(ctx, out, c.parseOptions()...)
+}
+
+// Like call, but if wait is set and the request is rate limited, it is sent
+// again once the limit resets.  The number of attempts and the total time
+// spent waiting are bounded by the client's RetryPolicy, or by the defaults
+// from NewRetryPolicy if none is set.  Once they are exhausted, the
+// RateLimitError is returned.  SendRequest does not also retry the calls,
+// so the policy's limits apply once rather than to each attempt.
+func (c *Client) callWaiting(ctx context.Context, wait bool, method string, path string, params url.Values, out interface{}) (err error) {
+	var (
+		policy = c.Retry
+		waited time.Duration
+	)
+	if !wait {
+		return c.call(ctx, method, path, params, out)
+	}
+	if policy == nil {
+		policy = NewRetryPolicy()
+	}
+	ctx = withoutRetry(ctx)
+	for attempt := 1; ; attempt++ {
+		err = c.call(ctx, method, path, params, out)
+		rle, limited := err.(RateLimitError)
+		if !limited {
+			return
+		}
+		d, retry := policy.nextLimited(attempt, waited, rle)
+		if !retry {
+			return
+		}
+		if err = sleepContext(ctx, d); err != nil {
+			return
+		}
+		waited += d
+	}
+}
+
+// Returns the most recent Tweets posted by the user in p.
+func (c *Client) UserTimeline(ctx context.Context, p UserTimelineParams) (timeline Timeline, err error) {
+	if err = p.Validate(); err != nil {
+		return
+	}
+	err = c.call(ctx, "GET", PATH_USER_TIMELINE, p.Values(), &timeline)
+	return
+}
+
+// Returns the most recent Tweets from the authenticating user and the
+// accounts they follow.
+func (c *Client) HomeTimeline(ctx context.Context, p TimelineParams) (timeline Timeline, err error) {
+	if err = p.Validate(); err != nil {
+		return
+	}
+	err = c.call(ctx, "GET", PATH_HOME_TIMELINE, p.Values(), &timeline)
+	return
+}
+
+// Returns the most recent Tweets mentioning the authenticating user.
+func (c *Client) MentionsTimeline(ctx context.Context, p TimelineParams) (timeline Timeline, err error) {
+	if err = p.Validate(); err != nil {
+		return
+	}
+	err = c.call(ctx, "GET", PATH_MENTIONS_TIMELINE, p.Values(), &timeline)
+	return
+}
+
+// Returns recent Tweets matching the query in p.
+func (c *Client) Search(ctx context.Context, p SearchParams) (results SearchResults, err error) {
+	if err = p.Validate(); err != nil {
+		return
+	}
+	err = c.call(ctx, "GET", PATH_SEARCH_TWEETS, p.Values(), &results)
+	return
+}
+
+// Returns the user identified in p.
+func (c *Client) ShowUser(ctx context.Context, p ShowUserParams) (user User, err error) {
+	if err = p.Validate(); err != nil {
+		return
+	}
+	err = c.call(ctx, "GET", PATH_SHOW_USER, p.Values(), &user)
+	return
+}
+
+// Posts a Tweet as the authenticating user.
+func (c *Client) UpdateStatus(ctx context.Context, p UpdateStatusParams) (tweet Tweet, err error) {
+	if err = p.Validate(); err != nil {
+		return
+	}
+	err = c.call(ctx, "POST", PATH_UPDATE_STATUS, p.Values(), &tweet)
+	return
+}
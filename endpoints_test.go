@@ -0,0 +1,154 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserTimeline(t *testing.T) {
+	var (
+		req    *http.Request
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req = r
+			fmt.Fprint(w, `[{"id_str":"2"},{"id_str":"1"}]`)
+		}))
+		c        = getHostTestClient(server)
+		timeline Timeline
+		err      error
+	)
+	defer server.Close()
+	timeline, err = c.UserTimeline(context.Background(), UserTimelineParams{
+		ScreenName:      "kurrik",
+		Count:           2,
+		ExcludeRetweets: true,
+		TweetMode:       "extended",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(timeline) != 2 || timeline[0].IdStr() != "2" {
+		t.Errorf("Timeline was not parsed correctly: %v", timeline)
+	}
+	if req.URL.Path != PATH_USER_TIMELINE {
+		t.Errorf("Request sent to wrong path: %v", req.URL.Path)
+	}
+	var q = req.URL.Query()
+	if q.Get("screen_name") != "kurrik" || q.Get("count") != "2" || q.Get("include_rts") != "false" || q.Get("tweet_mode") != "extended" {
+		t.Errorf("Request had incorrect query: %v", req.URL.RawQuery)
+	}
+}
+
+func TestUpdateStatus(t *testing.T) {
+	var (
+		req    *http.Request
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			req = r
+			fmt.Fprint(w, `{"id_str":"1234","text":"Hello"}`)
+		}))
+		c     = getHostTestClient(server)
+		tweet Tweet
+		err   error
+	)
+	defer server.Close()
+	tweet, err = c.UpdateStatus(context.Background(), UpdateStatusParams{
+		Status:   "Hello",
+		MediaIds: []string{"1", "2"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tweet.Text() != "Hello" {
+		t.Errorf("Tweet was not parsed correctly: %v", tweet)
+	}
+	if req.Method != "POST" || req.PostForm.Get("status") != "Hello" || req.PostForm.Get("media_ids") != "1,2" {
+		t.Errorf("Request had incorrect form: %v %v", req.Method, req.PostForm)
+	}
+}
+
+func TestEndpointErrorsAreReturned(t *testing.T) {
+	var (
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(STATUS_NOTFOUND)
+			fmt.Fprint(w, `{"errors":[{"code":50,"message":"User not found."}]}`)
+		}))
+		c   = getHostTestClient(server)
+		err error
+		ok  bool
+	)
+	defer server.Close()
+	_, err = c.ShowUser(context.Background(), ShowUserParams{ScreenName: "nobody"})
+	if _, ok = err.(Errors); !ok {
+		t.Errorf("Expected Errors, got %v", err)
+	}
+}
+
+func TestParamValidation(t *testing.T) {
+	var (
+		requests int
+		server   = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+		}))
+		c   = getHostTestClient(server)
+		ctx = context.Background()
+		err error
+	)
+	defer server.Close()
+	var checks = map[string]func() error{
+		"user_timeline without user": func() error {
+			_, err := c.UserTimeline(ctx, UserTimelineParams{Count: 10})
+			return err
+		},
+		"user_timeline count too high": func() error {
+			_, err := c.UserTimeline(ctx, UserTimelineParams{ScreenName: "kurrik", Count: 201})
+			return err
+		},
+		"search without query": func() error {
+			_, err := c.Search(ctx, SearchParams{})
+			return err
+		},
+		"search with bad result type": func() error {
+			_, err := c.Search(ctx, SearchParams{Query: "go", ResultType: "newest"})
+			return err
+		},
+		"show_user with both ids": func() error {
+			_, err := c.ShowUser(ctx, ShowUserParams{UserId: 1, ScreenName: "kurrik"})
+			return err
+		},
+		"update_status without status": func() error {
+			_, err := c.UpdateStatus(ctx, UpdateStatusParams{})
+			return err
+		},
+		"home_timeline with bad tweet mode": func() error {
+			_, err := c.HomeTimeline(ctx, TimelineParams{TweetMode: "full"})
+			return err
+		},
+	}
+	for name, check := range checks {
+		if err = check(); err == nil {
+			t.Errorf("%v: expected a validation error", name)
+		} else if _, ok := err.(ParamError); !ok {
+			t.Errorf("%v: expected a ParamError, got %v", name, err)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("Invalid requests should not be sent, got %v", requests)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Logger receives structured log records as a message followed by
+// alternating keys and values.  *slog.Logger implements it.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+const (
+	H_TRANSACTION_ID = "X-Transaction-Id"
+	REDACTED         = "[REDACTED]"
+)
+
+// Credentials which may appear in headers, query strings and bodies.
+const redactedNames = `oauth_signature|oauth_token|oauth_token_secret|oauth_consumer_key|oauth_verifier|` +
+	`access_token|access_token_secret|refresh_token|client_secret|code_verifier|` +
+	`consumer_key|consumer_secret|bearer_token`
+
+// Values may be cut short when a body is truncated, so closing quotes are
+// optional.
+var redactions = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// Authorization: OAuth oauth_token="..."
+	{regexp.MustCompile(`\b(` + redactedNames + `)="[^"]*"?`), `$1="` + REDACTED + `"`},
+	// JSON: "access_token":"..."
+	{regexp.MustCompile(`"(` + redactedNames + `)"(\s*:\s*)"[^"]*"?`), `"$1"$2"` + REDACTED + `"`},
+	// Query strings and form bodies: oauth_token=...
+	{regexp.MustCompile(`(^|[?&\s])(` + redactedNames + `)=[^&\s"]+`), `$1$2=` + REDACTED},
+	// Authorization: Bearer ...
+	{regexp.MustCompile(`\b(Bearer|Basic) [^\s",]+`), `$1 ` + REDACTED},
+}
+
+// Returns s with OAuth signatures, tokens, secrets and consumer keys
+// replaced by REDACTED.  It understands Authorization headers, query
+// strings, form bodies and JSON.
+func Redact(s string) string {
+	for _, r := range redactions {
+		s = r.re.ReplaceAllString(s, r.repl)
+	}
+	return s
+}
+
+// Returns a copy of h with credentials redacted, suitable for logging.
+func RedactHeader(h http.Header) http.Header {
+	var out = make(http.Header, len(h))
+	for name, values := range h {
+		var redacted = make([]string, len(values))
+		for i, v := range values {
+			redacted[i] = Redact(v)
+		}
+		out[name] = redacted
+	}
+	return out
+}
+
+// Returns at most limit bytes of b, redacted, noting whether any of the
+// total bytes were left out.
+func truncateBody(b []byte, total int, limit int) string {
+	if len(b) > limit {
+		b = b[:limit]
+	}
+	var s = Redact(string(b))
+	if total > len(b) {
+		s += "...(truncated)"
+	}
+	return s
+}
+
+// Logs each request sent by the client to its Logger.
+func (c *Client) logDoer(next Doer) Doer {
+	var (
+		logger = c.Logger
+		limit  = c.LogBodyLimit
+	)
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		var (
+			start = time.Now()
+			args  = []interface{}{"method", req.Method, "path", req.URL.Path}
+		)
+		if req.URL.RawQuery != "" {
+			args = append(args, "query", Redact(req.URL.RawQuery))
+		}
+		if limit > 0 && req.Body != nil && req.GetBody != nil {
+			if body, err := req.GetBody(); err == nil {
+				b, _ := ioutil.ReadAll(io.LimitReader(body, int64(limit)+1))
+				body.Close()
+				args = append(args, "request_body", truncateBody(b, len(b), limit))
+			}
+		}
+		resp, err := next.Do(req)
+		args = append(args, "latency", time.Since(start))
+		if err != nil {
+			logger.Error("twitter request failed", append(args, "error", err)...)
+			return resp, err
+		}
+		args = append(args, "status", resp.StatusCode)
+		for _, h := range []struct{ name, key string }{
+			{H_LIMIT, "rate_limit"},
+			{H_LIMIT_REMAIN, "rate_limit_remaining"},
+			{H_LIMIT_RESET, "rate_limit_reset"},
+			{H_TRANSACTION_ID, "transaction_id"},
+		} {
+			if v := resp.Header.Get(h.name); v != "" {
+				args = append(args, h.key, v)
+			}
+		}
+		if resp.StatusCode >= 400 {
+			logger.Warn("twitter request", args...)
+		} else {
+			logger.Info("twitter request", args...)
+		}
+		if limit > 0 && resp.Body != nil {
+			resp.Body = &loggedBody{
+				ReadCloser: resp.Body,
+				logger:     logger,
+				args:       []interface{}{"method", req.Method, "path", req.URL.Path, "status", resp.StatusCode},
+				limit:      limit,
+			}
+		}
+		return resp, err
+	})
+}
+
+// Captures the start of a response body as it is read, and logs it when
+// the body is closed, so that streams are not held up.
+type loggedBody struct {
+	io.ReadCloser
+	logger Logger
+	args   []interface{}
+	limit  int
+
+	mu     sync.Mutex
+	buf    []byte
+	total  int
+	logged bool
+}
+
+func (b *loggedBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.total += n
+	if room := b.limit - len(b.buf); room > 0 {
+		if room > n {
+			room = n
+		}
+		b.buf = append(b.buf, p[:room]...)
+	}
+	return
+}
+
+func (b *loggedBody) Close() error {
+	b.mu.Lock()
+	if !b.logged {
+		b.logged = true
+		b.logger.Info("twitter response body", append(b.args, "body", truncateBody(b.buf, b.total, b.limit))...)
+	}
+	b.mu.Unlock()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type logRecord struct {
+	level string
+	msg   string
+	attrs map[string]interface{}
+}
+
+// Records log calls for inspection.
+type testLogger struct {
+	mu      sync.Mutex
+	records []logRecord
+}
+
+func (l *testLogger) log(level string, msg string, args []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var r = logRecord{level, msg, map[string]interface{}{}}
+	for i := 0; i+1 < len(args); i += 2 {
+		r.attrs[args[i].(string)] = args[i+1]
+	}
+	l.records = append(l.records, r)
+}
+
+func (l *testLogger) Debug(msg string, args ...interface{}) { l.log("DEBUG", msg, args) }
+func (l *testLogger) Info(msg string, args ...interface{})  { l.log("INFO", msg, args) }
+func (l *testLogger) Warn(msg string, args ...interface{})  { l.log("WARN", msg, args) }
+func (l *testLogger) Error(msg string, args ...interface{}) { l.log("ERROR", msg, args) }
+
+func TestRedact(t *testing.T) {
+	var cases = map[string]string{
+		`OAuth oauth_consumer_key="key", oauth_nonce="n", oauth_signature="sig%3D", oauth_token="token"`: `OAuth oauth_consumer_key="[REDACTED]", oauth_nonce="n", oauth_signature="[REDACTED]", oauth_token="[REDACTED]"`,
+		`Bearer AAAA%2Fbbbb`:                               `Bearer [REDACTED]`,
+		`Basic a2V5OnNlY3JldA==`:                           `Basic [REDACTED]`,
+		`oauth_token=abc&oauth_verifier=xyz&foo=bar`:       `oauth_token=[REDACTED]&oauth_verifier=[REDACTED]&foo=bar`,
+		`q=go&count=5`:                                     `q=go&count=5`,
+		`{"token_type":"bearer","access_token":"AAAA"}`:    `{"token_type":"bearer","access_token":"[REDACTED]"}`,
+		`{"refresh_token": "abc", "scope":"tweet.read"}`:   `{"refresh_token": "[REDACTED]", "scope":"tweet.read"}`,
+		`{"access_token":"AAAA-cut-short`:                  `{"access_token":"[REDACTED]"`,
+		`grant_type=refresh_token&refresh_token=abc`:       `grant_type=refresh_token&refresh_token=[REDACTED]`,
+		`{"text":"mentions access_token in prose","id":1}`: `{"text":"mentions access_token in prose","id":1}`,
+	}
+	for in, expected := range cases {
+		if got := Redact(in); got != expected {
+			t.Errorf("Redact(%v) = %v, expected %v", in, got, expected)
+		}
+	}
+	var h = http.Header{"Authorization": {`Bearer secret`}, "Accept": {"*/*"}}
+	if got := RedactHeader(h); got.Get("Authorization") != "Bearer [REDACTED]" || got.Get("Accept") != "*/*" || h.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Unexpected redacted header %v", got)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(H_LIMIT, "15")
+		w.Header().Set(H_LIMIT_REMAIN, "14")
+		w.Header().Set(H_LIMIT_RESET, "2000000000")
+		w.Header().Set(H_TRANSACTION_ID, "abc123")
+		if r.URL.Path == "/1.1/missing.json" {
+			w.WriteHeader(STATUS_NOTFOUND)
+		}
+		fmt.Fprint(w, `{"access_token":"leaked","text":"`+strings.Repeat("x", 100)+`"}`)
+	}))
+	defer server.Close()
+
+	var (
+		c      = getTestClient(server)
+		logger = &testLogger{}
+	)
+	c.Logger = logger
+	req, _ := http.NewRequest("POST", server.URL+"/1.1/statuses/update.json?oauth_token=leaked", strings.NewReader("status=hi&access_token=leaked"))
+	resp, err := c.SendRequest(req)
+	if err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	resp.ReadBody()
+	if len(logger.records) != 1 {
+		t.Fatalf("Expected one record without body logging, got %v", logger.records)
+	}
+	var r = logger.records[0]
+	if r.level != "INFO" || r.attrs["method"] != "POST" || r.attrs["path"] != "/1.1/statuses/update.json" || r.attrs["status"] != 200 {
+		t.Errorf("Unexpected record %v", r)
+	}
+	if r.attrs["rate_limit_remaining"] != "14" || r.attrs["transaction_id"] != "abc123" || r.attrs["latency"] == nil {
+		t.Errorf("Missing response details in %v", r)
+	}
+
+	logger.records = nil
+	c.LogBodyLimit = 40
+	req, _ = http.NewRequest("POST", server.URL+"/1.1/missing.json", strings.NewReader("status=hi&access_token=leaked"))
+	resp, _ = c.WithUser(c.User).SendRequest(req)
+	resp.ReadBody()
+	if len(logger.records) != 2 || logger.records[0].level != "WARN" {
+		t.Fatalf("Expected a warning and a body record, got %v", logger.records)
+	}
+	if body := logger.records[0].attrs["request_body"]; body != "status=hi&access_token=[REDACTED]" {
+		t.Errorf("Unexpected request body %v", body)
+	}
+	if body := logger.records[1].attrs["body"].(string); !strings.HasPrefix(body, `{"access_token":"[REDACTED]"`) || !strings.HasSuffix(body, "...(truncated)") {
+		t.Errorf("Unexpected response body %v", body)
+	}
+	if all := fmt.Sprint(logger.records); strings.Contains(all, "leaked") {
+		t.Errorf("Credentials leaked into log: %v", all)
+	}
+}
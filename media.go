@@ -0,0 +1,260 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PATH_MEDIA_UPLOAD = "/1.1/media/upload.json"
+)
+
+const (
+	// Default size of each APPEND segment.  The API accepts up to 5MB.
+	MEDIA_CHUNK_SIZE = 1024 * 1024
+	// Default minimum time between STATUS polls.
+	MEDIA_POLL_INTERVAL = time.Second
+	// Default number of STATUS polls before giving up on processing.
+	MEDIA_MAX_POLLS = 60
+	// Longest the minimum time between STATUS polls is backed off to.
+	mediaPollIntervalMax = 30 * time.Second
+)
+
+// MediaUploader performs the chunked media upload flow (INIT, APPEND,
+// FINALIZE and STATUS) against the client's upload host.
+// https://developer.twitter.com/en/docs/twitter-api/v1/media/upload-media/uploading-media/chunked-media-upload
+type MediaUploader struct {
+	Client *Client
+	// Size of each APPEND segment in bytes.
+	ChunkSize int
+	// Optional media_category, e.g. tweet_image, tweet_gif or tweet_video.
+	// Required for video and GIFs which need server side processing.
+	Category string
+	// Minimum time between STATUS polls, used when the API does not say
+	// when to check again.  It doubles with each poll, up to 30 seconds.
+	PollInterval time.Duration
+	// Number of STATUS polls after which Upload gives up waiting for
+	// processing and returns an error.
+	MaxPolls int
+
+	exhausted time.Time
+}
+
+// Creates an uploader which sends requests through c.
+func NewMediaUploader(c *Client) *MediaUploader {
+	return &MediaUploader{
+		Client:       c,
+		ChunkSize:    MEDIA_CHUNK_SIZE,
+		PollInterval: MEDIA_POLL_INTERVAL,
+		MaxPolls:     MEDIA_MAX_POLLS,
+	}
+}
+
+// Uploads size bytes of media read from r and waits for any processing to
+// complete.  The media id of the returned response (see
+// MediaResponse.MediaIdStr) may be attached to a Tweet through
+// UpdateStatusParams.MediaIds.
+func (u *MediaUploader) Upload(ctx context.Context, r io.Reader, size int64, mediaType string) (media MediaResponse, err error) {
+	var (
+		init = url.Values{
+			"command":     []string{"INIT"},
+			"total_bytes": []string{strconv.FormatInt(size, 10)},
+			"media_type":  []string{mediaType},
+		}
+		id string
+	)
+	setString(init, "media_category", u.Category)
+	if media, err = u.post(ctx, init); err != nil {
+		return
+	}
+	if id = media.MediaIdStr(); id == "" {
+		err = fmt.Errorf("INIT response did not contain a media id")
+		return
+	}
+	if err = u.appendAll(ctx, id, r, size); err != nil {
+		return
+	}
+	if media, err = u.post(ctx, url.Values{
+		"command":  []string{"FINALIZE"},
+		"media_id": []string{id},
+	}); err != nil {
+		return
+	}
+	return u.wait(ctx, id, media)
+}
+
+// Sends the media in segments of at most ChunkSize bytes.
+func (u *MediaUploader) appendAll(ctx context.Context, id string, r io.Reader, size int64) (err error) {
+	var (
+		chunk = u.ChunkSize
+		buf   []byte
+		n     int
+		sent  int64
+	)
+	if chunk <= 0 {
+		chunk = MEDIA_CHUNK_SIZE
+	}
+	buf = make([]byte, chunk)
+	for segment := 0; sent < size; segment++ {
+		if remaining := size - sent; remaining < int64(chunk) {
+			buf = buf[:remaining]
+		}
+		if n, err = io.ReadFull(r, buf); err != nil {
+			return fmt.Errorf("Could not read segment %v: %v", segment, err)
+		}
+		if err = u.appendSegment(ctx, id, segment, buf[:n]); err != nil {
+			return
+		}
+		sent += int64(n)
+	}
+	return
+}
+
+// Sends a single APPEND request as multipart form data.
+func (u *MediaUploader) appendSegment(ctx context.Context, id string, segment int, data []byte) (err error) {
+	var (
+		body   = &bytes.Buffer{}
+		writer = multipart.NewWriter(body)
+		part   io.Writer
+		req    *http.Request
+		resp   *APIResponse
+	)
+	writer.WriteField("command", "APPEND")
+	writer.WriteField("media_id", id)
+	writer.WriteField("segment_index", strconv.Itoa(segment))
+	if part, err = writer.CreateFormFile("media", "media"); err != nil {
+		return
+	}
+	if _, err = part.Write(data); err != nil {
+		return
+	}
+	if err = writer.Close(); err != nil {
+		return
+	}
+	if req, err = http.NewRequestWithContext(ctx, "POST", u.url(), body); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if resp, err = u.send(ctx, req); err != nil {
+		return
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		discardBody(resp)
+		return
+	}
+	return resp.ParseContext(ctx, nil)
+}
+
+// Polls STATUS until processing of the media completes, or MaxPolls polls
+// have been made.
+func (u *MediaUploader) wait(ctx context.Context, id string, media MediaResponse) (MediaResponse, error) {
+	var (
+		info     ProcessingInfo
+		req      *http.Request
+		resp     *APIResponse
+		err      error
+		delay    time.Duration
+		interval = u.PollInterval
+		polls    = u.MaxPolls
+		q        = url.Values{
+			"command":  []string{"STATUS"},
+			"media_id": []string{id},
+		}
+	)
+	if interval <= 0 {
+		interval = MEDIA_POLL_INTERVAL
+	}
+	if polls <= 0 {
+		polls = MEDIA_MAX_POLLS
+	}
+	for poll := 0; ; poll++ {
+		info = media.ProcessingInfo()
+		switch info.State() {
+		case "", "succeeded":
+			return media, nil
+		case "failed":
+			return media, fmt.Errorf("Media processing failed: %v", info.ProcessingError().Message())
+		}
+		if poll >= polls {
+			return media, fmt.Errorf("Media processing did not complete after %v status checks", polls)
+		}
+		if delay = time.Duration(info.CheckAfterSecs()) * time.Second; delay < interval {
+			delay = interval
+			if interval < mediaPollIntervalMax {
+				interval = increase(interval*2, mediaPollIntervalMax)
+			}
+		}
+		if err = sleepContext(ctx, delay); err != nil {
+			return media, err
+		}
+		if req, err = http.NewRequestWithContext(ctx, "GET", u.url()+"?"+q.Encode(), nil); err != nil {
+			return media, err
+		}
+		if resp, err = u.send(ctx, req); err != nil {
+			return media, err
+		}
+		media = MediaResponse{}
+		if err = resp.ParseContext(ctx, &media, u.Client.parseOptions()...); err != nil {
+			return media, err
+		}
+	}
+}
+
+// Sends a form encoded command and parses the response.
+func (u *MediaUploader) post(ctx context.Context, data url.Values) (media MediaResponse, err error) {
+	var (
+		req  *http.Request
+		resp *APIResponse
+	)
+	req, err = http.NewRequestWithContext(ctx, "POST", u.url(), strings.NewReader(data.Encode()))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if resp, err = u.send(ctx, req); err != nil {
+		return
+	}
+	err = resp.ParseContext(ctx, &media, u.Client.parseOptions()...)
+	return
+}
+
+// Sends a request, first waiting for the media rate limit to reset if the
+// previous response reported it as exhausted.
+func (u *MediaUploader) send(ctx context.Context, req *http.Request) (resp *APIResponse, err error) {
+	if err = sleepContext(ctx, time.Until(u.exhausted)); err != nil {
+		return
+	}
+	if resp, err = u.Client.SendRequestContext(ctx, req); err != nil {
+		return
+	}
+	if resp.HasMediaRateLimit() && resp.MediaRateLimitRemaining() == 0 {
+		u.exhausted = resp.MediaRateLimitReset()
+	}
+	return
+}
+
+func (u *MediaUploader) url() string {
+	return fmt.Sprintf("https://%v%v", u.Client.UploadHost, PATH_MEDIA_UPLOAD)
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Returns a server implementing the chunked upload commands, which reports
+// the media as in_progress for the first `pending` STATUS calls.
+func getUploadServer(t *testing.T, pending int, received *bytes.Buffer, commands *[]string) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PATH_MEDIA_UPLOAD {
+			t.Errorf("Unexpected path %v", r.URL.Path)
+		}
+		var command = r.FormValue("command")
+		*commands = append(*commands, command)
+		w.Header().Set(H_MEDIA_LIMIT, "100")
+		w.Header().Set(H_MEDIA_LIMIT_REMAIN, "99")
+		w.Header().Set(H_MEDIA_LIMIT_RESET, "0")
+		switch command {
+		case "INIT":
+			fmt.Fprint(w, `{"media_id":710511363345354753,"media_id_string":"710511363345354753"}`)
+		case "APPEND":
+			file, _, err := r.FormFile("media")
+			if err != nil {
+				t.Errorf("APPEND did not include media: %v", err)
+			}
+			b, _ := ioutil.ReadAll(file)
+			received.Write(b)
+			w.WriteHeader(STATUS_NO_CONTENT)
+		case "FINALIZE":
+			fmt.Fprint(w, `{"media_id_string":"710511363345354753","processing_info":{"state":"pending","check_after_secs":0}}`)
+		case "STATUS":
+			if pending--; pending >= 0 {
+				fmt.Fprint(w, `{"media_id_string":"710511363345354753","processing_info":{"state":"in_progress","check_after_secs":0,"progress_percent":50}}`)
+			} else {
+				fmt.Fprint(w, `{"media_id_string":"710511363345354753","processing_info":{"state":"succeeded","progress_percent":100}}`)
+			}
+		}
+	}))
+}
+
+func TestMediaUpload(t *testing.T) {
+	var (
+		received bytes.Buffer
+		commands []string
+		server   = getUploadServer(t, 1, &received, &commands)
+		c        = getTestClient(server)
+		uploader = NewMediaUploader(c)
+		data     = strings.Repeat("0123456789", 10)
+		media    MediaResponse
+		err      error
+	)
+	defer server.Close()
+	c.UploadHost = server.Listener.Addr().String()
+	uploader.ChunkSize = 32
+	uploader.Category = "tweet_video"
+	uploader.PollInterval = time.Millisecond
+	media, err = uploader.Upload(context.Background(), strings.NewReader(data), int64(len(data)), "video/mp4")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if media.MediaIdStr() != "710511363345354753" {
+		t.Errorf("Unexpected media id %v", media.MediaIdStr())
+	}
+	if received.String() != data {
+		t.Errorf("Uploaded data did not match, got %v", received.String())
+	}
+	var expected = "INIT,APPEND,APPEND,APPEND,APPEND,FINALIZE,STATUS,STATUS"
+	if strings.Join(commands, ",") != expected {
+		t.Errorf("Expected commands %v, got %v", expected, commands)
+	}
+}
+
+func TestMediaUploadShortReader(t *testing.T) {
+	var (
+		received bytes.Buffer
+		commands []string
+		server   = getUploadServer(t, 0, &received, &commands)
+		c        = getTestClient(server)
+		uploader = NewMediaUploader(c)
+		err      error
+	)
+	defer server.Close()
+	c.UploadHost = server.Listener.Addr().String()
+	_, err = uploader.Upload(context.Background(), strings.NewReader("short"), 100, "image/png")
+	if err == nil {
+		t.Fatalf("Expected an error when the reader is shorter than size")
+	}
+}
+
+func TestMediaProcessingMaxPolls(t *testing.T) {
+	var (
+		received bytes.Buffer
+		commands []string
+		server   = getUploadServer(t, 10, &received, &commands)
+		c        = getTestClient(server)
+		uploader = NewMediaUploader(c)
+		start    = time.Now()
+		err      error
+	)
+	defer server.Close()
+	c.UploadHost = server.Listener.Addr().String()
+	uploader.PollInterval = 10 * time.Millisecond
+	uploader.MaxPolls = 3
+	_, err = uploader.Upload(context.Background(), strings.NewReader("data"), 4, "video/mp4")
+	if err == nil || !strings.Contains(err.Error(), "did not complete") {
+		t.Fatalf("Expected processing to time out, got %v", err)
+	}
+	var expected = "INIT,APPEND,FINALIZE,STATUS,STATUS,STATUS"
+	if strings.Join(commands, ",") != expected {
+		t.Errorf("Expected commands %v, got %v", expected, commands)
+	}
+	// Polls back off from 10ms: 10 + 20 + 40.
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("Expected polls to back off, took %v", elapsed)
+	}
+}
+
+func TestMediaProcessingFailed(t *testing.T) {
+	var (
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.FormValue("command") {
+			case "INIT":
+				fmt.Fprint(w, `{"media_id_string":"1"}`)
+			case "APPEND":
+				w.WriteHeader(STATUS_NO_CONTENT)
+			case "FINALIZE":
+				fmt.Fprint(w, `{"media_id_string":"1","processing_info":{"state":"failed","error":{"code":1,"name":"InvalidMedia","message":"Unsupported video format"}}}`)
+			}
+		}))
+		c   = getTestClient(server)
+		err error
+	)
+	defer server.Close()
+	c.UploadHost = server.Listener.Addr().String()
+	_, err = NewMediaUploader(c).Upload(context.Background(), strings.NewReader("data"), 4, "video/mp4")
+	if err == nil || !strings.Contains(err.Error(), "Unsupported video format") {
+		t.Errorf("Expected processing failure, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"net/http"
+)
+
+// Doer sends an HTTP request.  *http.Client is a Doer.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Adapts a function to the Doer interface.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the Doer which sends a client's requests.  The request
+// passed to the returned Doer is already signed; each retry is signed and
+// passed through the chain again.
+type Middleware func(next Doer) Doer
+
+// Adds middleware around the client's HttpClient.  The first middleware
+// added is the outermost, so it sees each request first and each response
+// last.  Views created by WithUser afterwards share the middleware.  Like
+// SetUser, this is not safe to call while other goroutines are using the
+// client.
+func (c *Client) Use(middleware ...Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// Calls f with each signed request before it is sent.  f may add headers.
+func (c *Client) OnRequest(f func(req *http.Request)) {
+	c.Use(func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			f(req)
+			return next.Do(req)
+		})
+	})
+}
+
+// Calls f with each response, or with the error if no response was
+// received.  f must not read the response body.
+func (c *Client) OnResponse(f func(req *http.Request, resp *http.Response, err error)) {
+	c.Use(func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.Do(req)
+			f(req, resp, err)
+			return resp, err
+		})
+	})
+}
+
+// Calls f whenever a request is rejected because a rate limit is
+// exhausted, with the limit reported by the response.
+func (c *Client) OnRateLimited(f func(req *http.Request, limit RateLimitError)) {
+	c.OnResponse(func(req *http.Request, resp *http.Response, err error) {
+		if err != nil || resp.StatusCode != STATUS_LIMIT {
+			return
+		}
+		var r = (*APIResponse)(resp)
+		f(req, RateLimitError{
+			Limit:     r.RateLimit(),
+			Remaining: r.RateLimitRemaining(),
+			Reset:     r.RateLimitReset(),
+		})
+	})
+}
+
+// Returns the client's HttpClient wrapped in its middleware.  The logger,
+// if any, is innermost so that it records requests as they are sent.
+func (c *Client) doer() Doer {
+	var d Doer = c.HttpClient
+	if c.Logger != nil {
+		d = c.logDoer(d)
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		d = c.middleware[i](d)
+	}
+	return d
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	var requests = 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Trace") != "trace" {
+			t.Errorf("Missing header added by OnRequest")
+		}
+		if requests == 1 {
+			w.Header().Set(H_LIMIT, "15")
+			w.Header().Set(H_LIMIT_REMAIN, "0")
+			w.Header().Set(H_LIMIT_RESET, "1")
+			w.WriteHeader(STATUS_LIMIT)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	var (
+		c       = getTestClient(server)
+		calls   []string
+		limited []RateLimitError
+		named   = func(name string) Middleware {
+			return func(next Doer) Doer {
+				return DoerFunc(func(req *http.Request) (*http.Response, error) {
+					if !strings.HasPrefix(req.Header.Get("Authorization"), "OAuth ") {
+						t.Errorf("Middleware saw unsigned request")
+					}
+					calls = append(calls, name+">")
+					resp, err := next.Do(req)
+					calls = append(calls, "<"+name)
+					return resp, err
+				})
+			}
+		}
+	)
+	c.Retry = &RetryPolicy{MaxAttempts: 2, MaxWait: time.Second}
+	c.Use(named("outer"), named("inner"))
+	c.OnRequest(func(req *http.Request) {
+		req.Header.Set("X-Trace", "trace")
+	})
+	c.OnRateLimited(func(req *http.Request, limit RateLimitError) {
+		limited = append(limited, limit)
+	})
+
+	req, _ := http.NewRequest("GET", server.URL+"/1.1/search/tweets.json?q=go", nil)
+	resp, err := c.WithUser(c.User).SendRequest(req)
+	if err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	if resp.StatusCode != STATUS_OK {
+		t.Errorf("Expected retry to succeed, got %v", resp.StatusCode)
+	}
+	var expected = []string{"outer>", "inner>", "<inner", "<outer", "outer>", "inner>", "<inner", "<outer"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Unexpected middleware calls %v", calls)
+	}
+	if len(limited) != 1 || limited[0].Limit != 15 || limited[0].Remaining != 0 {
+		t.Errorf("Unexpected rate limit hooks %v", limited)
+	}
+}
@@ -15,7 +15,9 @@
 package twittergo
 
 import (
+	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -49,6 +51,13 @@ const (
 	STATUS_GATEWAY      = 502
 )
 
+// Error codes returned in the body of error responses.
+// https://developer.twitter.com/en/support/twitter-api/error-troubleshooting
+const (
+	ERROR_INVALID_TOKEN           = 89
+	ERROR_TIMESTAMP_OUT_OF_BOUNDS = 135
+)
+
 // Error returned if there was an issue parsing the response body.
 type ResponseError struct {
 	Body string
@@ -114,6 +123,16 @@ func (e Errors) Errors() []Error {
 	return out
 }
 
+// Returns true if one of the errors has the supplied code.
+func (e Errors) HasCode(code int64) bool {
+	for _, err := range e.Errors() {
+		if err.Code() == code {
+			return true
+		}
+	}
+	return false
+}
+
 // RateLimitResponse is implemented by both RateLimitError and APIResponse.
 type RateLimitResponse interface {
 	// HasRateLimit returns false if the ratelimiting information is
@@ -207,17 +226,28 @@ func (r APIResponse) MediaRateLimitReset() time.Time {
 	return t
 }
 
-func (r APIResponse) readBody() (b []byte, err error) {
+// Wraps a reader so that reads fail once the context is done.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r contextReader) Read(p []byte) (n int, err error) {
+	if err = r.ctx.Err(); err != nil {
+		return
+	}
+	return r.reader.Read(p)
+}
+
+func (r APIResponse) readBody(ctx context.Context) (b []byte, err error) {
 	var (
 		header string
-		reader io.Reader
+		reader io.Reader = contextReader{ctx, r.Body}
 	)
 	defer r.Body.Close()
 	header = strings.ToLower(r.Header.Get("Content-Encoding"))
-	if header == "" || strings.Index(header, "gzip") == -1 {
-		reader = r.Body
-	} else {
-		if reader, err = gzip.NewReader(r.Body); err != nil {
+	if header != "" && strings.Index(header, "gzip") != -1 {
+		if reader, err = gzip.NewReader(reader); err != nil {
 			return
 		}
 	}
@@ -225,6 +255,47 @@ func (r APIResponse) readBody() (b []byte, err error) {
 	return
 }
 
+// A reader which always fails with err.
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+// Returns true if the response is a 401 whose body contains an error with
+// the supplied code.  The body is left unread so that it may still be
+// parsed.
+func (r *APIResponse) hasErrorCode(ctx context.Context, code int64) bool {
+	if r.StatusCode != STATUS_UNAUTHORIZED || r.Body == nil {
+		return false
+	}
+	var (
+		raw  []byte
+		body []byte
+		errs Errors
+		err  error
+	)
+	raw, err = ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		// Leave the read error for whoever parses the response.
+		r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(raw), errorReader{err}))
+		return false
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(raw))
+	var peek = *r
+	peek.Body = ioutil.NopCloser(bytes.NewReader(raw))
+	if body, err = peek.readBody(ctx); err != nil {
+		return false
+	}
+	if json.Unmarshal(body, &errs) != nil {
+		return false
+	}
+	return errs.HasCode(code)
+}
+
 // ReadBody returns the body of the response as a string.
 // Only one of ReadBody and Parse may be called on a given APIResponse.
 func (r APIResponse) ReadBody() string {
@@ -232,19 +303,47 @@ func (r APIResponse) ReadBody() string {
 		b   []byte
 		err error
 	)
-	if b, err = r.readBody(); err != nil {
+	if b, err = r.readBody(context.Background()); err != nil {
 		return ""
 	}
 	return string(b)
 }
 
+// ParseOption changes how APIResponse.Parse decodes a response.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	useNumber bool
+}
+
+// UseNumber decodes JSON numbers into json.Number instead of float64, so
+// that 64-bit IDs and counts keep their exact values.  The accessors on
+// the standard models understand either representation.
+func UseNumber() ParseOption {
+	return func(o *parseOptions) {
+		o.useNumber = true
+	}
+}
+
 // Parse unmarshals a JSON encoded HTTP response into the supplied interface,
 // with handling for the various kinds of errors the Twitter API can return.
 //
 // The returned error may be of the type Errors, RateLimitError,
 // ResponseError, or an error returned from io.Reader.Read().
-func (r APIResponse) Parse(out interface{}) (err error) {
-	var b []byte
+func (r APIResponse) Parse(out interface{}, opts ...ParseOption) (err error) {
+	return r.ParseContext(context.Background(), out, opts...)
+}
+
+// ParseContext is like Parse, but stops reading the response body and
+// returns the context's error if ctx is done first.
+func (r APIResponse) ParseContext(ctx context.Context, out interface{}, opts ...ParseOption) (err error) {
+	var (
+		b []byte
+		o parseOptions
+	)
+	for _, opt := range opts {
+		opt(&o)
+	}
 	switch r.StatusCode {
 	case STATUS_UNAUTHORIZED:
 		fallthrough
@@ -256,7 +355,7 @@ func (r APIResponse) Parse(out interface{}) (err error) {
 		fallthrough
 	case STATUS_INVALID:
 		e := &Errors{}
-		if b, err = r.readBody(); err != nil {
+		if b, err = r.readBody(ctx); err != nil {
 			return
 		}
 		if err = json.Unmarshal(b, e); err != nil {
@@ -272,7 +371,7 @@ func (r APIResponse) Parse(out interface{}) (err error) {
 			Reset:     r.RateLimitReset(),
 		}
 		// consume the request body even if we don't need it
-		r.readBody()
+		r.readBody(ctx)
 		return
 	case STATUS_NO_CONTENT:
 		return
@@ -281,15 +380,21 @@ func (r APIResponse) Parse(out interface{}) (err error) {
 	case STATUS_ACCEPTED:
 		fallthrough
 	case STATUS_OK:
-		if b, err = r.readBody(); err != nil {
+		if b, err = r.readBody(ctx); err != nil {
 			return
 		}
-		err = json.Unmarshal(b, out)
+		if o.useNumber {
+			d := json.NewDecoder(bytes.NewReader(b))
+			d.UseNumber()
+			err = d.Decode(out)
+		} else {
+			err = json.Unmarshal(b, out)
+		}
 		if err == io.EOF {
 			err = nil
 		}
 	default:
-		if b, err = r.readBody(); err != nil {
+		if b, err = r.readBody(ctx); err != nil {
 			return
 		}
 		err = NewResponseError(r.StatusCode, string(b))
@@ -569,6 +674,46 @@ func (cl CursoredLists) Lists() Lists {
 	return b
 }
 
+// It's a cursored list of user IDs!
+type CursoredIDs map[string]interface{}
+
+func (ci CursoredIDs) NextCursorStr() string {
+	return stringValue(ci, "next_cursor_str")
+}
+
+func (ci CursoredIDs) PreviousCursorStr() string {
+	return stringValue(ci, "previous_cursor_str")
+}
+
+func (ci CursoredIDs) IDs() []uint64 {
+	var a []interface{} = arrayValue(ci, "ids")
+	b := make([]uint64, len(a))
+	for i, v := range a {
+		b[i] = idValue(v)
+	}
+	return b
+}
+
+// It's a cursored list of Users!
+type CursoredUsers map[string]interface{}
+
+func (cu CursoredUsers) NextCursorStr() string {
+	return stringValue(cu, "next_cursor_str")
+}
+
+func (cu CursoredUsers) PreviousCursorStr() string {
+	return stringValue(cu, "previous_cursor_str")
+}
+
+func (cu CursoredUsers) Users() []User {
+	var a []interface{} = arrayValue(cu, "users")
+	b := make([]User, len(a))
+	for i, v := range a {
+		b[i] = v.(map[string]interface{})
+	}
+	return b
+}
+
 // Nested response structure for video uploads.
 type VideoUpload map[string]interface{}
 
@@ -583,6 +728,10 @@ func (r MediaResponse) MediaId() int64 {
 	return int64Value(r, "media_id")
 }
 
+func (r MediaResponse) MediaIdStr() string {
+	return stringValue(r, "media_id_string")
+}
+
 func (r MediaResponse) Size() int64 {
 	return int64Value(r, "size")
 }
@@ -594,3 +743,29 @@ func (r MediaResponse) ExpiresAfterSecs() int32 {
 func (r MediaResponse) Video() VideoUpload {
 	return VideoUpload(mapValue(r, "video"))
 }
+
+func (r MediaResponse) ProcessingInfo() ProcessingInfo {
+	return ProcessingInfo(mapValue(r, "processing_info"))
+}
+
+// Asynchronous processing state for uploaded video and GIFs.
+type ProcessingInfo map[string]interface{}
+
+// One of pending, in_progress, failed or succeeded.  Empty if the media
+// does not require processing.
+func (p ProcessingInfo) State() string {
+	return stringValue(p, "state")
+}
+
+func (p ProcessingInfo) CheckAfterSecs() int64 {
+	return int64Value(p, "check_after_secs")
+}
+
+func (p ProcessingInfo) ProgressPercent() int64 {
+	return int64Value(p, "progress_percent")
+}
+
+// Returns the reason processing failed, if the state is failed.
+func (p ProcessingInfo) ProcessingError() Error {
+	return Error(mapValue(p, "error"))
+}
@@ -258,3 +258,49 @@ func TestEmptyErrorWith403(t *testing.T) {
 		t.Errorf("ResponseError body should be ``, got `%s`", rerr.Body)
 	}
 }
+
+func TestParseUseNumber(t *testing.T) {
+	var (
+		body     = `{"media_id":1002011200236892166,"size":11065,"expires_after_secs":86400}`
+		api_resp *APIResponse
+		media    MediaResponse
+		err      error
+	)
+	api_resp = (*APIResponse)(getResponse(200, body))
+	media = MediaResponse{}
+	if err = api_resp.Parse(&media); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if media.MediaId() == 1002011200236892166 {
+		t.Errorf("Expected precision to be lost without UseNumber")
+	}
+	api_resp = (*APIResponse)(getResponse(200, body))
+	media = MediaResponse{}
+	if err = api_resp.Parse(&media, UseNumber()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if media.MediaId() != 1002011200236892166 {
+		t.Errorf("Expected exact media id, got %v", media.MediaId())
+	}
+	if media.Size() != 11065 || media.ExpiresAfterSecs() != 86400 {
+		t.Errorf("Unexpected size %v or expiry %v", media.Size(), media.ExpiresAfterSecs())
+	}
+}
+
+func TestParseUseNumberCursors(t *testing.T) {
+	var (
+		body     = `{"ids":[1002011200236892166],"next_cursor":1002011200236892167,"next_cursor_str":"1002011200236892167"}`
+		api_resp = (*APIResponse)(getResponse(200, body))
+		ids      = CursoredIDs{}
+		err      error
+	)
+	if err = api_resp.Parse(&ids, UseNumber()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ids.IDs()[0] != 1002011200236892166 {
+		t.Errorf("Expected exact id, got %v", ids.IDs()[0])
+	}
+	if stringValue(ids, "next_cursor") != ids.NextCursorStr() {
+		t.Errorf("Expected exact cursor, got %v", stringValue(ids, "next_cursor"))
+	}
+}
@@ -0,0 +1,439 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// The types in this file are typed counterparts of the map based models,
+// covering the v1.1 data dictionary.  Either form may be passed to
+// APIResponse.Parse, and the ToV1 and ToMap methods convert between them.
+// Fields which the API may return as null are pointers.
+
+// Typed form of Tweet.
+// https://developer.twitter.com/en/docs/twitter-api/v1/data-dictionary/object-model/tweet
+type TweetV1 struct {
+	CreatedAt            string            `json:"created_at"`
+	Id                   int64             `json:"id"`
+	IdStr                string            `json:"id_str"`
+	Text                 string            `json:"text,omitempty"`
+	FullText             string            `json:"full_text,omitempty"`
+	DisplayTextRange     Range             `json:"display_text_range,omitempty"`
+	Source               string            `json:"source,omitempty"`
+	Truncated            bool              `json:"truncated"`
+	InReplyToStatusId    *int64            `json:"in_reply_to_status_id"`
+	InReplyToStatusIdStr *string           `json:"in_reply_to_status_id_str"`
+	InReplyToUserId      *int64            `json:"in_reply_to_user_id"`
+	InReplyToUserIdStr   *string           `json:"in_reply_to_user_id_str"`
+	InReplyToScreenName  *string           `json:"in_reply_to_screen_name"`
+	User                 *UserV1           `json:"user,omitempty"`
+	Coordinates          *CoordinatesV1    `json:"coordinates"`
+	Place                *PlaceV1          `json:"place"`
+	QuotedStatusId       int64             `json:"quoted_status_id,omitempty"`
+	QuotedStatusIdStr    string            `json:"quoted_status_id_str,omitempty"`
+	IsQuoteStatus        bool              `json:"is_quote_status"`
+	QuotedStatus         *TweetV1          `json:"quoted_status,omitempty"`
+	RetweetedStatus      *TweetV1          `json:"retweeted_status,omitempty"`
+	QuoteCount           *int64            `json:"quote_count,omitempty"`
+	ReplyCount           *int64            `json:"reply_count,omitempty"`
+	RetweetCount         int64             `json:"retweet_count"`
+	FavoriteCount        *int64            `json:"favorite_count"`
+	Entities             *EntitiesV1       `json:"entities,omitempty"`
+	ExtendedEntities     *EntitiesV1       `json:"extended_entities,omitempty"`
+	ExtendedTweet        *ExtendedTweetV1  `json:"extended_tweet,omitempty"`
+	Favorited            *bool             `json:"favorited"`
+	Retweeted            bool              `json:"retweeted"`
+	PossiblySensitive    *bool             `json:"possibly_sensitive,omitempty"`
+	FilterLevel          string            `json:"filter_level,omitempty"`
+	Lang                 *string           `json:"lang"`
+	MatchingRules        []MatchingRuleV1  `json:"matching_rules,omitempty"`
+	CurrentUserRetweet   *CurrentRetweetV1 `json:"current_user_retweet,omitempty"`
+	Scopes               map[string]bool   `json:"scopes,omitempty"`
+	WithheldCopyright    bool              `json:"withheld_copyright,omitempty"`
+	WithheldInCountries  []string          `json:"withheld_in_countries,omitempty"`
+	WithheldScope        string            `json:"withheld_scope,omitempty"`
+}
+
+// Returns the time the Tweet was created, or the zero time if the
+// created_at field could not be parsed.
+func (t TweetV1) CreatedAtTime() time.Time {
+	return parseCreatedAt(t.CreatedAt)
+}
+
+// Converts the Tweet to its map based form.
+func (t TweetV1) ToMap() (out Tweet, err error) {
+	err = convertModel(t, &out)
+	return
+}
+
+// Converts the Tweet to its typed form.
+func (t Tweet) ToV1() (out TweetV1, err error) {
+	err = convertModel(t, &out)
+	return
+}
+
+// The full text and entities of a Tweet longer than 140 characters, as
+// delivered in compatibility mode.
+type ExtendedTweetV1 struct {
+	FullText         string      `json:"full_text"`
+	DisplayTextRange Range       `json:"display_text_range,omitempty"`
+	Entities         *EntitiesV1 `json:"entities,omitempty"`
+	ExtendedEntities *EntitiesV1 `json:"extended_entities,omitempty"`
+}
+
+// A filtered stream rule which matched a Tweet.
+type MatchingRuleV1 struct {
+	Tag   *string `json:"tag"`
+	Id    int64   `json:"id"`
+	IdStr string  `json:"id_str"`
+}
+
+// The authenticating user's retweet of a Tweet.
+type CurrentRetweetV1 struct {
+	Id    int64  `json:"id"`
+	IdStr string `json:"id_str"`
+}
+
+// Typed form of User.
+// https://developer.twitter.com/en/docs/twitter-api/v1/data-dictionary/object-model/user
+type UserV1 struct {
+	Id                   int64           `json:"id"`
+	IdStr                string          `json:"id_str"`
+	Name                 string          `json:"name"`
+	ScreenName           string          `json:"screen_name"`
+	Location             *string         `json:"location"`
+	Derived              *DerivedV1      `json:"derived,omitempty"`
+	URL                  *string         `json:"url"`
+	Description          *string         `json:"description"`
+	Protected            bool            `json:"protected"`
+	Verified             bool            `json:"verified"`
+	FollowersCount       int64           `json:"followers_count"`
+	FriendsCount         int64           `json:"friends_count"`
+	ListedCount          int64           `json:"listed_count"`
+	FavouritesCount      int64           `json:"favourites_count"`
+	StatusesCount        int64           `json:"statuses_count"`
+	CreatedAt            string          `json:"created_at"`
+	ProfileBannerURL     string          `json:"profile_banner_url,omitempty"`
+	ProfileImageURL      string          `json:"profile_image_url,omitempty"`
+	ProfileImageURLHttps string          `json:"profile_image_url_https,omitempty"`
+	DefaultProfile       bool            `json:"default_profile"`
+	DefaultProfileImage  bool            `json:"default_profile_image"`
+	Following            *bool           `json:"following,omitempty"`
+	FollowRequestSent    *bool           `json:"follow_request_sent,omitempty"`
+	Notifications        *bool           `json:"notifications,omitempty"`
+	Entities             *UserEntitiesV1 `json:"entities,omitempty"`
+	Status               *TweetV1        `json:"status,omitempty"`
+	WithheldInCountries  []string        `json:"withheld_in_countries,omitempty"`
+	WithheldScope        string          `json:"withheld_scope,omitempty"`
+}
+
+// Returns the time the account was created, or the zero time if the
+// created_at field could not be parsed.
+func (u UserV1) CreatedAtTime() time.Time {
+	return parseCreatedAt(u.CreatedAt)
+}
+
+// Converts the user to its map based form.
+func (u UserV1) ToMap() (out User, err error) {
+	err = convertModel(u, &out)
+	return
+}
+
+// Converts the user to its typed form.
+func (u User) ToV1() (out UserV1, err error) {
+	err = convertModel(u, &out)
+	return
+}
+
+// Entities parsed from a user's profile URL and description.
+type UserEntitiesV1 struct {
+	URL         *EntitiesV1 `json:"url,omitempty"`
+	Description *EntitiesV1 `json:"description,omitempty"`
+}
+
+// Enrichments derived from a user's profile, available to enterprise
+// customers.
+type DerivedV1 struct {
+	Locations []DerivedLocationV1 `json:"locations,omitempty"`
+}
+
+type DerivedLocationV1 struct {
+	Country     string         `json:"country,omitempty"`
+	CountryCode string         `json:"country_code,omitempty"`
+	Locality    string         `json:"locality,omitempty"`
+	Region      string         `json:"region,omitempty"`
+	SubRegion   string         `json:"sub_region,omitempty"`
+	FullName    string         `json:"full_name,omitempty"`
+	Geo         *CoordinatesV1 `json:"geo,omitempty"`
+}
+
+// Typed form of Entities.
+// https://developer.twitter.com/en/docs/twitter-api/v1/data-dictionary/object-model/entities
+type EntitiesV1 struct {
+	Hashtags     []HashtagV1     `json:"hashtags,omitempty"`
+	Media        []MediaV1       `json:"media,omitempty"`
+	URLs         []URLV1         `json:"urls,omitempty"`
+	UserMentions []UserMentionV1 `json:"user_mentions,omitempty"`
+	Symbols      []SymbolV1      `json:"symbols,omitempty"`
+	Polls        []PollV1        `json:"polls,omitempty"`
+}
+
+// Converts the entities to their map based form.
+func (e EntitiesV1) ToMap() (out Entities, err error) {
+	err = convertModel(e, &out)
+	return
+}
+
+// Converts the entities to their typed form.
+func (e Entities) ToV1() (out EntitiesV1, err error) {
+	err = convertModel(e, &out)
+	return
+}
+
+// Typed form of Hashtag.
+type HashtagV1 struct {
+	Indices Range  `json:"indices"`
+	Text    string `json:"text"`
+}
+
+// Typed form of Media.
+type MediaV1 struct {
+	DisplayURL          string                 `json:"display_url"`
+	ExpandedURL         string                 `json:"expanded_url"`
+	Id                  int64                  `json:"id"`
+	IdStr               string                 `json:"id_str"`
+	Indices             Range                  `json:"indices"`
+	MediaURL            string                 `json:"media_url"`
+	MediaURLHttps       string                 `json:"media_url_https"`
+	Sizes               *MediaSizesV1          `json:"sizes,omitempty"`
+	SourceStatusId      int64                  `json:"source_status_id,omitempty"`
+	SourceStatusIdStr   string                 `json:"source_status_id_str,omitempty"`
+	Type                string                 `json:"type"`
+	URL                 string                 `json:"url"`
+	VideoInfo           *VideoInfoV1           `json:"video_info,omitempty"`
+	AdditionalMediaInfo *AdditionalMediaInfoV1 `json:"additional_media_info,omitempty"`
+	ExtAltText          *string                `json:"ext_alt_text,omitempty"`
+}
+
+type MediaSizesV1 struct {
+	Thumb  *MediaSizeV1 `json:"thumb,omitempty"`
+	Small  *MediaSizeV1 `json:"small,omitempty"`
+	Medium *MediaSizeV1 `json:"medium,omitempty"`
+	Large  *MediaSizeV1 `json:"large,omitempty"`
+}
+
+type MediaSizeV1 struct {
+	W      int    `json:"w"`
+	H      int    `json:"h"`
+	Resize string `json:"resize"`
+}
+
+// Playback details for video and animated GIF media.
+type VideoInfoV1 struct {
+	AspectRatio    []int       `json:"aspect_ratio"`
+	DurationMillis int64       `json:"duration_millis,omitempty"`
+	Variants       []VariantV1 `json:"variants"`
+}
+
+type VariantV1 struct {
+	Bitrate     int64  `json:"bitrate,omitempty"`
+	ContentType string `json:"content_type"`
+	URL         string `json:"url"`
+}
+
+type AdditionalMediaInfoV1 struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Embeddable  *bool  `json:"embeddable,omitempty"`
+	Monetizable bool   `json:"monetizable"`
+}
+
+// Typed form of URL.
+type URLV1 struct {
+	DisplayURL  string     `json:"display_url"`
+	ExpandedURL string     `json:"expanded_url"`
+	Indices     Range      `json:"indices"`
+	URL         string     `json:"url"`
+	Unwound     *UnwoundV1 `json:"unwound,omitempty"`
+}
+
+// Details of the final destination of a URL, available to enterprise
+// customers.
+type UnwoundV1 struct {
+	URL         string `json:"url"`
+	Status      int    `json:"status"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// Typed form of UserMention.
+type UserMentionV1 struct {
+	Id         int64  `json:"id"`
+	IdStr      string `json:"id_str"`
+	Indices    Range  `json:"indices"`
+	Name       string `json:"name"`
+	ScreenName string `json:"screen_name"`
+}
+
+// Typed form of Symbol.
+type SymbolV1 struct {
+	Indices Range  `json:"indices"`
+	Text    string `json:"text"`
+}
+
+// Typed form of Poll.
+type PollV1 struct {
+	Options         []PollOptionV1 `json:"options"`
+	EndDatetime     string         `json:"end_datetime"`
+	DurationMinutes int64          `json:"duration_minutes"`
+}
+
+type PollOptionV1 struct {
+	Position int    `json:"position"`
+	Text     string `json:"text"`
+}
+
+// A point on the globe, as [longitude, latitude].
+// https://developer.twitter.com/en/docs/twitter-api/v1/data-dictionary/object-model/geo
+type CoordinatesV1 struct {
+	Coordinates []float64 `json:"coordinates"`
+	Type        string    `json:"type"`
+}
+
+// A named location which a Tweet may be associated with.
+type PlaceV1 struct {
+	Id          string            `json:"id"`
+	URL         string            `json:"url"`
+	PlaceType   string            `json:"place_type"`
+	Name        string            `json:"name"`
+	FullName    string            `json:"full_name"`
+	CountryCode string            `json:"country_code"`
+	Country     string            `json:"country"`
+	BoundingBox *BoundingBoxV1    `json:"bounding_box,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+}
+
+// A polygon enclosing a place, as rings of [longitude, latitude] points.
+type BoundingBoxV1 struct {
+	Coordinates [][][]float64 `json:"coordinates"`
+	Type        string        `json:"type"`
+}
+
+// Typed form of List.
+// https://developer.twitter.com/en/docs/twitter-api/v1/accounts-and-users/create-manage-lists/api-reference/get-lists-show
+type ListV1 struct {
+	Id              int64   `json:"id"`
+	IdStr           string  `json:"id_str"`
+	Name            string  `json:"name"`
+	URI             string  `json:"uri"`
+	SubscriberCount int64   `json:"subscriber_count"`
+	MemberCount     int64   `json:"member_count"`
+	Mode            string  `json:"mode"`
+	Description     string  `json:"description"`
+	Slug            string  `json:"slug"`
+	FullName        string  `json:"full_name"`
+	CreatedAt       string  `json:"created_at"`
+	Following       bool    `json:"following"`
+	User            *UserV1 `json:"user,omitempty"`
+}
+
+// Returns the time the list was created, or the zero time if the
+// created_at field could not be parsed.
+func (l ListV1) CreatedAtTime() time.Time {
+	return parseCreatedAt(l.CreatedAt)
+}
+
+// Converts the list to its map based form.
+func (l ListV1) ToMap() (out List, err error) {
+	err = convertModel(l, &out)
+	return
+}
+
+// Converts the list to its typed form.
+func (l List) ToV1() (out ListV1, err error) {
+	err = convertModel(l, &out)
+	return
+}
+
+// Typed form of Timeline.
+type TimelineV1 []TweetV1
+
+// Converts the timeline to its map based form.
+func (tl TimelineV1) ToMap() (out Timeline, err error) {
+	err = convertModel(tl, &out)
+	return
+}
+
+// Converts the timeline to its typed form.
+func (tl Timeline) ToV1() (out TimelineV1, err error) {
+	err = convertModel(tl, &out)
+	return
+}
+
+// Typed form of SearchResults.
+type SearchResultsV1 struct {
+	Statuses       []TweetV1        `json:"statuses"`
+	SearchMetadata SearchMetadataV1 `json:"search_metadata"`
+}
+
+type SearchMetadataV1 struct {
+	CompletedIn float64 `json:"completed_in"`
+	MaxId       int64   `json:"max_id"`
+	MaxIdStr    string  `json:"max_id_str"`
+	SinceId     int64   `json:"since_id"`
+	SinceIdStr  string  `json:"since_id_str"`
+	NextResults string  `json:"next_results,omitempty"`
+	RefreshURL  string  `json:"refresh_url,omitempty"`
+	Query       string  `json:"query"`
+	Count       int     `json:"count"`
+}
+
+// Converts the results to their map based form.
+func (sr SearchResultsV1) ToMap() (out SearchResults, err error) {
+	err = convertModel(sr, &out)
+	return
+}
+
+// Converts the results to their typed form.
+func (sr SearchResults) ToV1() (out SearchResultsV1, err error) {
+	err = convertModel(sr, &out)
+	return
+}
+
+// Parses the created_at format used throughout the v1.1 API.
+func parseCreatedAt(src string) time.Time {
+	out, err := time.Parse(time.RubyDate, src)
+	if err != nil {
+		return time.Time{} // Could not parse time
+	}
+	return out
+}
+
+// Converts between model forms by round tripping through JSON.  Numbers are
+// decoded as json.Number so that IDs survive conversion to a map exactly.
+// Maps parsed without UseNumber hold IDs as float64, which may already have
+// lost precision; the *_str fields are always exact.
+func convertModel(in interface{}, out interface{}) (err error) {
+	var b []byte
+	if b, err = json.Marshal(in); err != nil {
+		return
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return d.Decode(out)
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"testing"
+	"time"
+)
+
+const tweetV1Body = `{
+	"created_at": "Wed Oct 10 20:19:24 +0000 2018",
+	"id": 1050118621198921728,
+	"id_str": "1050118621198921728",
+	"text": "To make room for more expression #hashtag",
+	"truncated": false,
+	"in_reply_to_status_id": 1050118621198921700,
+	"in_reply_to_status_id_str": "1050118621198921700",
+	"in_reply_to_user_id": null,
+	"in_reply_to_user_id_str": null,
+	"in_reply_to_screen_name": null,
+	"user": {
+		"id": 6253282,
+		"id_str": "6253282",
+		"name": "Twitter API",
+		"screen_name": "TwitterAPI",
+		"location": "San Francisco, CA",
+		"followers_count": 6133636,
+		"created_at": "Wed May 23 06:01:13 +0000 2007"
+	},
+	"coordinates": {"coordinates": [-75.14310264, 40.05701649], "type": "Point"},
+	"place": {
+		"id": "07d9db48bc083000",
+		"place_type": "city",
+		"full_name": "Philadelphia, PA",
+		"bounding_box": {
+			"coordinates": [[[-75.28, 39.87], [-75.28, 40.13], [-74.95, 40.13], [-74.95, 39.87]]],
+			"type": "Polygon"
+		}
+	},
+	"is_quote_status": false,
+	"retweet_count": 161,
+	"favorite_count": 296,
+	"entities": {
+		"hashtags": [{"indices": [33, 41], "text": "hashtag"}],
+		"urls": [],
+		"user_mentions": []
+	},
+	"favorited": false,
+	"retweeted": false,
+	"lang": "en"
+}`
+
+func TestParseTweetV1(t *testing.T) {
+	var (
+		resp  = (*APIResponse)(getResponse(200, tweetV1Body))
+		tweet TweetV1
+		err   error
+	)
+	if err = resp.Parse(&tweet); err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if tweet.Id != 1050118621198921728 {
+		t.Errorf("Id not parsed exactly, got %v", tweet.Id)
+	}
+	if tweet.InReplyToStatusId == nil || *tweet.InReplyToStatusId != 1050118621198921700 {
+		t.Errorf("Unexpected in_reply_to_status_id %v", tweet.InReplyToStatusId)
+	}
+	if tweet.InReplyToUserId != nil {
+		t.Errorf("Expected null in_reply_to_user_id, got %v", *tweet.InReplyToUserId)
+	}
+	if tweet.RetweetCount != 161 || *tweet.FavoriteCount != 296 {
+		t.Errorf("Unexpected counts %v, %v", tweet.RetweetCount, *tweet.FavoriteCount)
+	}
+	if tweet.User.FollowersCount != 6133636 {
+		t.Errorf("Unexpected followers_count %v", tweet.User.FollowersCount)
+	}
+	if tweet.Coordinates.Coordinates[1] != 40.05701649 {
+		t.Errorf("Unexpected coordinates %v", tweet.Coordinates.Coordinates)
+	}
+	if tweet.Place.FullName != "Philadelphia, PA" || len(tweet.Place.BoundingBox.Coordinates[0]) != 4 {
+		t.Errorf("Unexpected place %v", tweet.Place)
+	}
+	if h := tweet.Entities.Hashtags; len(h) != 1 || h[0].Text != "hashtag" || h[0].Indices[1] != 41 {
+		t.Errorf("Unexpected hashtags %v", h)
+	}
+	if !tweet.CreatedAtTime().Equal(time.Date(2018, 10, 10, 20, 19, 24, 0, time.UTC)) {
+		t.Errorf("Unexpected created_at %v", tweet.CreatedAtTime())
+	}
+}
+
+func TestTweetV1Conversions(t *testing.T) {
+	var (
+		resp  = (*APIResponse)(getResponse(200, tweetV1Body))
+		tweet Tweet
+		typed TweetV1
+		back  Tweet
+		err   error
+	)
+	if err = resp.Parse(&tweet, UseNumber()); err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if typed, err = tweet.ToV1(); err != nil {
+		t.Fatalf("ToV1 returned error: %v", err)
+	}
+	if typed.Id != 1050118621198921728 {
+		t.Errorf("Id not converted exactly, got %v", typed.Id)
+	}
+	if typed.User.ScreenName != tweet.User().ScreenName() {
+		t.Errorf("Unexpected screen name %v", typed.User.ScreenName)
+	}
+	if back, err = typed.ToMap(); err != nil {
+		t.Fatalf("ToMap returned error: %v", err)
+	}
+	if back.Id() != tweet.Id() || back.Text() != tweet.Text() {
+		t.Errorf("Round trip changed the Tweet: %v", back)
+	}
+	if int64Value(back, "retweet_count") != 161 {
+		t.Errorf("Unexpected retweet_count %v", back["retweet_count"])
+	}
+	if len(back.Entities().Hashtags()) != 1 {
+		t.Errorf("Entities lost in round trip: %v", back.Entities())
+	}
+}
+
+func TestSearchResultsV1Conversions(t *testing.T) {
+	var (
+		results = SearchResults{
+			"statuses": []interface{}{
+				map[string]interface{}{"id_str": "2", "text": "two"},
+			},
+			"search_metadata": map[string]interface{}{
+				"next_results": "?max_id=1&q=golang",
+				"count":        float64(15),
+			},
+		}
+		typed SearchResultsV1
+		err   error
+	)
+	if typed, err = results.ToV1(); err != nil {
+		t.Fatalf("ToV1 returned error: %v", err)
+	}
+	if len(typed.Statuses) != 1 || typed.Statuses[0].Text != "two" {
+		t.Errorf("Unexpected statuses %v", typed.Statuses)
+	}
+	if typed.SearchMetadata.NextResults != "?max_id=1&q=golang" || typed.SearchMetadata.Count != 15 {
+		t.Errorf("Unexpected metadata %v", typed.SearchMetadata)
+	}
+}
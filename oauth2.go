@@ -0,0 +1,344 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	OAUTH2_AUTHORIZE_URL = "https://twitter.com/i/oauth2/authorize"
+	PATH_OAUTH2_TOKEN    = "/2/oauth2/token"
+)
+
+// Access tokens are refreshed when they are this close to expiring.
+const OAUTH2_REFRESH_MARGIN = time.Minute
+
+// Scopes for OAuth 2.0 user context requests.  SCOPE_OFFLINE_ACCESS must
+// be requested to receive a refresh token.
+// https://developer.twitter.com/en/docs/authentication/oauth-2-0/authorization-code
+const (
+	SCOPE_TWEET_READ     = "tweet.read"
+	SCOPE_TWEET_WRITE    = "tweet.write"
+	SCOPE_USERS_READ     = "users.read"
+	SCOPE_FOLLOWS_READ   = "follows.read"
+	SCOPE_FOLLOWS_WRITE  = "follows.write"
+	SCOPE_LIST_READ      = "list.read"
+	SCOPE_LIST_WRITE     = "list.write"
+	SCOPE_LIKE_READ      = "like.read"
+	SCOPE_LIKE_WRITE     = "like.write"
+	SCOPE_BOOKMARK_READ  = "bookmark.read"
+	SCOPE_BOOKMARK_WRITE = "bookmark.write"
+	SCOPE_OFFLINE_ACCESS = "offline.access"
+)
+
+// Configuration for the OAuth 2.0 Authorization Code flow with PKCE.
+// ClientSecret is only set for confidential clients.
+type OAuth2Config struct {
+	ClientId     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// Defaults to OAUTH2_AUTHORIZE_URL.
+	AuthorizeURL string
+	// Defaults to PATH_OAUTH2_TOKEN on the client's host.
+	TokenURL string
+}
+
+// An OAuth 2.0 user context access token.  It may be serialized and stored
+// for later use with SetUserToken.
+type OAuth2Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type"`
+	Scope        string    `json:"scope,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// Returns true if the token expires within margin of now.  Tokens without
+// an expiry never expire.
+func (t *OAuth2Token) Expired(margin time.Duration) bool {
+	return !t.Expiry.IsZero() && time.Now().Add(margin).After(t.Expiry)
+}
+
+// Error returned by the OAuth 2.0 token endpoint.
+type OAuth2Error struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e OAuth2Error) Error() string {
+	return fmt.Sprintf("OAuth2 error %v (status code %d): %v", e.Code, e.StatusCode, e.Description)
+}
+
+// The state of an authorization started by BeginOAuth2.  Keep it, for
+// example in the user's session, until the redirect arrives.
+type OAuth2Authorization struct {
+	// Send the user here.
+	URL string
+	// Compare with the state parameter of the redirect.
+	State        string
+	CodeVerifier string
+}
+
+// Returns a random URL safe string encoding n bytes.
+func randomString(n int) (string, error) {
+	var b = make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Starts the Authorization Code flow using the client's OAuth2 config.
+// Send the user to the returned URL; once they approve, Twitter redirects
+// them to the RedirectURL with code and state parameters.
+func (c *Client) BeginOAuth2() (auth *OAuth2Authorization, err error) {
+	if c.OAuth2 == nil {
+		return nil, fmt.Errorf("Client has no OAuth2 config")
+	}
+	auth = &OAuth2Authorization{}
+	if auth.State, err = randomString(32); err != nil {
+		return
+	}
+	if auth.CodeVerifier, err = randomString(32); err != nil {
+		return
+	}
+	var (
+		sum    = sha256.Sum256([]byte(auth.CodeVerifier))
+		u      = c.OAuth2.AuthorizeURL
+		params = url.Values{}
+	)
+	if u == "" {
+		u = OAUTH2_AUTHORIZE_URL
+	}
+	params.Set("response_type", "code")
+	params.Set("client_id", c.OAuth2.ClientId)
+	params.Set("redirect_uri", c.OAuth2.RedirectURL)
+	params.Set("scope", strings.Join(c.OAuth2.Scopes, " "))
+	params.Set("state", auth.State)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(sum[:]))
+	params.Set("code_challenge_method", "S256")
+	auth.URL = u + "?" + params.Encode()
+	return
+}
+
+// Exchanges the code from the redirect for an access token, which is
+// stored on the client and returned.  The state parameter of the redirect
+// must match auth.State.  If the client was selected with WithAccount, the
+// token is also saved to its TokenStore.
+func (c *Client) CompleteOAuth2(ctx context.Context, auth *OAuth2Authorization, state string, code string) (token *OAuth2Token, err error) {
+	if c.OAuth2 == nil {
+		return nil, fmt.Errorf("Client has no OAuth2 config")
+	}
+	if state != auth.State {
+		return nil, fmt.Errorf("OAuth2 state did not match")
+	}
+	var params = url.Values{}
+	params.Set("grant_type", "authorization_code")
+	params.Set("code", code)
+	params.Set("redirect_uri", c.OAuth2.RedirectURL)
+	params.Set("code_verifier", auth.CodeVerifier)
+	if token, err = c.requestOAuth2Token(ctx, params); err != nil {
+		return
+	}
+	var mu = &c.root().oauth2Mu
+	mu.Lock()
+	c.UserToken = token
+	var account = c.accountWithToken(token)
+	mu.Unlock()
+	err = c.storeAccount(account)
+	return
+}
+
+// Exchanges the current user token's refresh token for a new access token.
+// If a refresh is already in progress, waits for it instead.
+func (c *Client) RefreshUserToken(ctx context.Context) (err error) {
+	return c.refreshUserToken(ctx, nil)
+}
+
+// Refreshes the user token.  Concurrent callers wait for a single refresh of
+// the same token rather than each refreshing it.  If stale is set and the
+// token has already been replaced, nothing is done.  oauth2Mu is only held
+// while the client's fields are read and written, so the token request,
+// OnUserTokenRefresh and the TokenStore do not block other requests.
+func (c *Client) refreshUserToken(ctx context.Context, stale *OAuth2Token) (err error) {
+	var mu = &c.root().oauth2Mu
+	for {
+		mu.Lock()
+		if stale != nil && c.UserToken != stale {
+			mu.Unlock()
+			return
+		}
+		if f := c.userFetch; f != nil {
+			mu.Unlock()
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			// Try again if the refresh only failed because the context of
+			// the goroutine which made it was done.
+			if f.err == nil || (!errors.Is(f.err, context.Canceled) && !errors.Is(f.err, context.DeadlineExceeded)) {
+				return f.err
+			}
+			continue
+		}
+		if c.OAuth2 == nil {
+			mu.Unlock()
+			return fmt.Errorf("Client has no OAuth2 config")
+		}
+		if c.UserToken == nil || c.UserToken.RefreshToken == "" {
+			mu.Unlock()
+			return fmt.Errorf("No OAuth2 refresh token")
+		}
+		var (
+			f       = &tokenFetch{done: make(chan struct{})}
+			refresh = c.UserToken.RefreshToken
+			params  = url.Values{}
+			token   *OAuth2Token
+			account *StoredAccount
+		)
+		params.Set("grant_type", "refresh_token")
+		params.Set("refresh_token", refresh)
+		c.userFetch = f
+		mu.Unlock()
+		token, f.err = c.requestOAuth2Token(ctx, params)
+		if f.err == nil && token.RefreshToken == "" {
+			// The server may keep the refresh token the same without
+			// sending it again.
+			token.RefreshToken = refresh
+		}
+		mu.Lock()
+		c.userFetch = nil
+		if f.err == nil {
+			c.UserToken = token
+			account = c.accountWithToken(token)
+		}
+		mu.Unlock()
+		close(f.done)
+		if f.err != nil {
+			return f.err
+		}
+		if c.OnUserTokenRefresh != nil {
+			c.OnUserTokenRefresh(token)
+		}
+		return c.storeAccount(account)
+	}
+}
+
+// Sets the OAuth 2.0 user context token for this client.  Requests are
+// signed with it unless an OAuth 1.0a user is also set.
+func (c *Client) SetUserToken(token *OAuth2Token) {
+	var mu = &c.root().oauth2Mu
+	mu.Lock()
+	defer mu.Unlock()
+	c.UserToken = token
+}
+
+// Returns the current OAuth 2.0 user token.
+func (c *Client) userToken() *OAuth2Token {
+	var mu = &c.root().oauth2Mu
+	mu.Lock()
+	defer mu.Unlock()
+	return c.UserToken
+}
+
+// Posts params to the token endpoint and parses the resulting token.
+func (c *Client) requestOAuth2Token(ctx context.Context, params url.Values) (token *OAuth2Token, err error) {
+	var (
+		req  *http.Request
+		resp *http.Response
+		body []byte
+		u    = c.OAuth2.TokenURL
+		rj   struct {
+			OAuth2Token
+			ExpiresIn int64 `json:"expires_in"`
+		}
+	)
+	if u == "" {
+		u = fmt.Sprintf("https://%v%v", c.Host, PATH_OAUTH2_TOKEN)
+	}
+	params.Set("client_id", c.OAuth2.ClientId)
+	if req, err = newParamsRequest(ctx, "POST", u, params); err != nil {
+		return
+	}
+	if c.OAuth2.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.OAuth2.ClientId), url.QueryEscape(c.OAuth2.ClientSecret))
+	}
+	if resp, err = c.HttpClient.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if body, err = ioutil.ReadAll(resp.Body); err != nil {
+		return
+	}
+	if resp.StatusCode != STATUS_OK {
+		var oerr = OAuth2Error{StatusCode: resp.StatusCode}
+		if json.Unmarshal(body, &oerr) != nil || oerr.Code == "" {
+			return nil, NewResponseError(resp.StatusCode, string(body))
+		}
+		return nil, oerr
+	}
+	if err = json.Unmarshal(body, &rj); err != nil {
+		return nil, NewResponseError(resp.StatusCode, string(body))
+	}
+	if rj.AccessToken == "" || !strings.EqualFold(rj.TokenType, "bearer") {
+		return nil, fmt.Errorf("Got invalid OAuth2 token type: %v", rj.TokenType)
+	}
+	token = &rj.OAuth2Token
+	if rj.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(rj.ExpiresIn) * time.Second)
+	}
+	return
+}
+
+// Signs the request with the OAuth 2.0 user token, refreshing it first if
+// it is about to expire and can be refreshed.  Concurrent requests wait for
+// a single refresh.
+func (c *Client) signOAuth2(ctx context.Context, req *http.Request) (err error) {
+	var token = c.userToken()
+	if token == nil {
+		return fmt.Errorf("No OAuth2 user token")
+	}
+	if token.Expired(OAUTH2_REFRESH_MARGIN) && token.RefreshToken != "" && c.OAuth2 != nil {
+		if err = c.refreshUserToken(ctx, token); err != nil {
+			return
+		}
+		token = c.userToken()
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return
+}
+
+// Returns a rate limit identity for an OAuth 2.0 user token which does not
+// reveal the token itself.
+func oauth2Identity(token *OAuth2Token) string {
+	sum := sha256.Sum256([]byte(token.AccessToken))
+	return "oauth2:" + hex.EncodeToString(sum[:8])
+}
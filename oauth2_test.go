@@ -0,0 +1,218 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOAuth2Flow(t *testing.T) {
+	var (
+		challenge string
+		refreshes int
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case PATH_OAUTH2_TOKEN:
+			r.ParseForm()
+			switch r.Form.Get("grant_type") {
+			case "authorization_code":
+				sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+				if base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+					t.Errorf("Code verifier does not match challenge")
+				}
+				if r.Form.Get("code") != "code" || r.Form.Get("client_id") != "client" {
+					t.Errorf("Unexpected token request %v", r.Form)
+				}
+				// Already inside the refresh margin.
+				fmt.Fprint(w, `{"token_type":"bearer","access_token":"access1","refresh_token":"refresh1","expires_in":30,"scope":"tweet.read offline.access"}`)
+			case "refresh_token":
+				refreshes++
+				if r.Form.Get("refresh_token") != "refresh1" {
+					t.Errorf("Unexpected refresh token %v", r.Form.Get("refresh_token"))
+				}
+				fmt.Fprint(w, `{"token_type":"bearer","access_token":"access2","refresh_token":"refresh2","expires_in":7200}`)
+			}
+		case "/2/users/me":
+			if auth := r.Header.Get("Authorization"); auth != "Bearer access2" {
+				t.Errorf("Request signed with %v", auth)
+			}
+			fmt.Fprint(w, `{"data":{"id":"1"}}`)
+		}
+	}))
+	defer server.Close()
+
+	var (
+		c         = getHostTestClient(server)
+		auth      *OAuth2Authorization
+		authURL   *url.URL
+		token     *OAuth2Token
+		refreshed *OAuth2Token
+		req       *http.Request
+		resp      *APIResponse
+		err       error
+	)
+	c.User = nil
+	c.OAuth2 = &OAuth2Config{
+		ClientId:    "client",
+		RedirectURL: "https://example.com/callback",
+		Scopes:      []string{SCOPE_TWEET_READ, SCOPE_OFFLINE_ACCESS},
+	}
+	c.OnUserTokenRefresh = func(token *OAuth2Token) {
+		refreshed = token
+	}
+	if auth, err = c.BeginOAuth2(); err != nil {
+		t.Fatalf("BeginOAuth2 returned error: %v", err)
+	}
+	authURL, _ = url.Parse(auth.URL)
+	challenge = authURL.Query().Get("code_challenge")
+	if authURL.Query().Get("scope") != "tweet.read offline.access" || authURL.Query().Get("state") != auth.State {
+		t.Errorf("Unexpected authorize URL %v", auth.URL)
+	}
+	if _, err = c.CompleteOAuth2(context.Background(), auth, "wrong", "code"); err == nil {
+		t.Errorf("Expected error for mismatched state")
+	}
+	if token, err = c.CompleteOAuth2(context.Background(), auth, auth.State, "code"); err != nil {
+		t.Fatalf("CompleteOAuth2 returned error: %v", err)
+	}
+	if token.AccessToken != "access1" || time.Until(token.Expiry) > time.Minute {
+		t.Errorf("Unexpected token %v", token)
+	}
+
+	req, _ = http.NewRequest("GET", "/2/users/me", nil)
+	if resp, err = c.SendRequest(req); err != nil {
+		t.Fatalf("SendRequest returned error: %v", err)
+	}
+	resp.Body.Close()
+	if refreshes != 1 || refreshed == nil || refreshed.AccessToken != "access2" {
+		t.Errorf("Expected token to be refreshed once, got %v %v", refreshes, refreshed)
+	}
+	if c.UserToken.RefreshToken != "refresh2" {
+		t.Errorf("Refreshed token not stored: %v", c.UserToken)
+	}
+}
+
+func TestOAuth2TokenError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		fmt.Fprint(w, `{"error":"invalid_request","error_description":"Value passed for the token was invalid."}`)
+	}))
+	defer server.Close()
+
+	var c = getHostTestClient(server)
+	c.OAuth2 = &OAuth2Config{ClientId: "client"}
+	c.UserToken = &OAuth2Token{AccessToken: "access", RefreshToken: "refresh"}
+	err := c.RefreshUserToken(context.Background())
+	if oerr, ok := err.(OAuth2Error); !ok || oerr.Code != "invalid_request" || oerr.StatusCode != 400 {
+		t.Errorf("Expected OAuth2Error, got %v", err)
+	}
+	if c.UserToken.AccessToken != "access" {
+		t.Errorf("Token changed after failed refresh")
+	}
+}
+
+func TestOAuth2RefreshKeepsRefreshToken(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token_type":"bearer","access_token":"access2","expires_in":7200}`)
+	}))
+	defer server.Close()
+
+	var c = getHostTestClient(server)
+	c.OAuth2 = &OAuth2Config{ClientId: "client"}
+	c.UserToken = &OAuth2Token{AccessToken: "access1", RefreshToken: "refresh"}
+	if err := c.RefreshUserToken(context.Background()); err != nil {
+		t.Fatalf("RefreshUserToken returned error: %v", err)
+	}
+	if c.UserToken.AccessToken != "access2" || c.UserToken.RefreshToken != "refresh" {
+		t.Errorf("Expected the refresh token to be kept, got %v", c.UserToken)
+	}
+}
+
+func TestOAuth2ConcurrentRefresh(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		refreshes int
+		started   = make(chan struct{})
+		release   = make(chan struct{})
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case PATH_OAUTH2_TOKEN:
+			mu.Lock()
+			refreshes++
+			mu.Unlock()
+			close(started)
+			<-release
+			fmt.Fprint(w, `{"token_type":"bearer","access_token":"access2","refresh_token":"refresh2","expires_in":7200}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer server.Close()
+
+	var (
+		c         = getHostTestClient(server)
+		expired   = c.WithUser(nil)
+		unrelated = c.WithUser(nil)
+		callbacks = make(chan string, 1)
+		wg        sync.WaitGroup
+	)
+	c.OAuth2 = &OAuth2Config{ClientId: "client"}
+	expired.OAuth2 = c.OAuth2
+	unrelated.OAuth2 = c.OAuth2
+	expired.UserToken = &OAuth2Token{AccessToken: "access1", RefreshToken: "refresh1", Expiry: time.Now()}
+	unrelated.UserToken = &OAuth2Token{AccessToken: "other"}
+	expired.OnUserTokenRefresh = func(token *OAuth2Token) {
+		// Touching the client from the callback must not deadlock.
+		callbacks <- expired.userToken().AccessToken
+		unrelated.SetUserToken(unrelated.userToken())
+	}
+	var send = func(view *Client) {
+		req, _ := http.NewRequest("GET", "/2/users/me", nil)
+		resp, err := view.SendRequest(req)
+		if err != nil {
+			t.Errorf("SendRequest returned error: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			send(expired)
+		}()
+	}
+	<-started
+	// Requests for other accounts are not held up by the refresh.
+	send(unrelated)
+	close(release)
+	wg.Wait()
+	if refreshes != 1 {
+		t.Errorf("Expected a single refresh, got %v", refreshes)
+	}
+	if got := <-callbacks; got != "access2" {
+		t.Errorf("Expected callback to see the new token, got %v", got)
+	}
+}
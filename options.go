@@ -0,0 +1,253 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/kurrik/oauth1a"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ClientOption configures a client created by NewClientWithOptions.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	apiHost    string
+	uploadHost string
+	streamHost string
+
+	httpClient *http.Client
+	transport  http.RoundTripper
+
+	// Settings for the transport created when neither httpClient nor
+	// transport is supplied.
+	proxy                 *url.URL
+	rootCAs               *x509.CertPool
+	dialTimeout           time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	transportSet          []string
+
+	timeout   time.Duration
+	userAgent string
+}
+
+// Sends API requests to host instead of api.twitter.com.  The OAuth 1.0a
+// authorization URLs use the same host.
+func WithAPIHost(host string) ClientOption {
+	return func(o *clientOptions) {
+		o.apiHost = host
+	}
+}
+
+// Sends media uploads to host instead of upload.twitter.com.
+func WithUploadHost(host string) ClientOption {
+	return func(o *clientOptions) {
+		o.uploadHost = host
+	}
+}
+
+// Connects streams to host instead of stream.twitter.com.
+func WithStreamHost(host string) ClientOption {
+	return func(o *clientOptions) {
+		o.streamHost = host
+	}
+}
+
+// Sends requests with client.  It cannot be combined with WithTransport or
+// the options which configure the transport.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.httpClient = client
+	}
+}
+
+// Sends requests with transport.  It cannot be combined with WithHTTPClient
+// or the options which configure the transport.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(o *clientOptions) {
+		o.transport = transport
+	}
+}
+
+// Sends requests through the proxy at proxyURL, which may use the http,
+// https or socks5 scheme.  The environment is not consulted.
+func WithProxy(proxyURL *url.URL) ClientOption {
+	return func(o *clientOptions) {
+		o.proxy = proxyURL
+		o.transportSet = append(o.transportSet, "WithProxy")
+	}
+}
+
+// Verifies server certificates against pool instead of the system roots.
+func WithRootCAs(pool *x509.CertPool) ClientOption {
+	return func(o *clientOptions) {
+		o.rootCAs = pool
+		o.transportSet = append(o.transportSet, "WithRootCAs")
+	}
+}
+
+// Limits how long connecting to the server may take.  Defaults to 30
+// seconds.
+func WithDialTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.dialTimeout = d
+		o.transportSet = append(o.transportSet, "WithDialTimeout")
+	}
+}
+
+// Limits how long the TLS handshake may take.  Defaults to 10 seconds.
+func WithTLSHandshakeTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.tlsHandshakeTimeout = d
+		o.transportSet = append(o.transportSet, "WithTLSHandshakeTimeout")
+	}
+}
+
+// Limits how long to wait for response headers once a request is sent.
+// Unlike WithTimeout, this is safe to use with streams.
+func WithResponseHeaderTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.responseHeaderTimeout = d
+		o.transportSet = append(o.transportSet, "WithResponseHeaderTimeout")
+	}
+}
+
+// Limits how long each request may take, including reading the response
+// body.  Streams are cut off after this long, so prefer contexts or
+// WithResponseHeaderTimeout for clients which stream.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeout = d
+	}
+}
+
+// Sets the User-Agent header of requests which do not already have one.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// Creates a new Twitter client configured by opts rather than by the
+// environment.  Unlike NewClient, it ignores $HTTP_PROXY and $TLS_INSECURE.
+// For example:
+//
+//	client, err := twittergo.NewClientWithOptions(config, user,
+//		twittergo.WithProxy(proxyURL),
+//		twittergo.WithTimeout(30*time.Second),
+//		twittergo.WithUserAgent("mybot/1.0"))
+func NewClientWithOptions(config *oauth1a.ClientConfig, user *oauth1a.UserConfig, opts ...ClientOption) (*Client, error) {
+	var o = clientOptions{
+		apiHost:             DEFAULT_API_HOST,
+		uploadHost:          DEFAULT_UPLOAD_HOST,
+		streamHost:          DEFAULT_STREAM_HOST,
+		dialTimeout:         30 * time.Second,
+		tlsHandshakeTimeout: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	httpClient, err := o.newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	var c = newClient(config, user, httpClient, o.apiHost)
+	c.UploadHost = o.uploadHost
+	c.StreamHost = o.streamHost
+	return c, nil
+}
+
+// Returns the HTTP client described by the options.
+func (o *clientOptions) newHTTPClient() (*http.Client, error) {
+	switch {
+	case o.httpClient != nil && o.transport != nil:
+		return nil, fmt.Errorf("WithHTTPClient cannot be combined with WithTransport")
+	case (o.httpClient != nil || o.transport != nil) && len(o.transportSet) > 0:
+		return nil, fmt.Errorf("%v cannot be combined with WithHTTPClient or WithTransport", o.transportSet[0])
+	}
+	var httpClient = &http.Client{}
+	if o.httpClient != nil {
+		// Copied so that the caller's client is left as it was.
+		*httpClient = *o.httpClient
+	} else if o.transport != nil {
+		httpClient.Transport = o.transport
+	} else {
+		transport, err := o.newTransport()
+		if err != nil {
+			return nil, err
+		}
+		httpClient.Transport = transport
+	}
+	if o.timeout != 0 {
+		httpClient.Timeout = o.timeout
+	}
+	if o.userAgent != "" {
+		var base = httpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		httpClient.Transport = &userAgentTransport{base, o.userAgent}
+	}
+	return httpClient, nil
+}
+
+func (o *clientOptions) newTransport() (*http.Transport, error) {
+	var transport = &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   o.dialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   o.tlsHandshakeTimeout,
+		ResponseHeaderTimeout: o.responseHeaderTimeout,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	if o.proxy != nil {
+		switch o.proxy.Scheme {
+		case "http", "https", "socks5":
+			transport.Proxy = http.ProxyURL(o.proxy)
+		default:
+			return nil, fmt.Errorf("Unsupported proxy scheme %v", o.proxy.Scheme)
+		}
+	}
+	if o.rootCAs != nil {
+		transport.TLSClientConfig = &tls.Config{RootCAs: o.rootCAs}
+	}
+	return transport, nil
+}
+
+// Sets the User-Agent header of requests which do not have one.
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") != "" {
+		return t.base.RoundTrip(req)
+	}
+	// RoundTrippers must not modify the request.
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"crypto/x509"
+	"fmt"
+	"github.com/kurrik/oauth1a"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClientWithOptions(t *testing.T) {
+	var agent string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agent = r.Header.Get("User-Agent")
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	var (
+		config = &oauth1a.ClientConfig{ConsumerKey: "key", ConsumerSecret: "secret"}
+		user   = oauth1a.NewAuthorizedConfig("token", "secret")
+		host   = strings.TrimPrefix(server.URL, "https://")
+		pool   = x509.NewCertPool()
+	)
+	pool.AddCert(server.Certificate())
+	c, err := NewClientWithOptions(config, user,
+		WithAPIHost(host),
+		WithUploadHost("upload.example.com"),
+		WithStreamHost("stream.example.com"),
+		WithRootCAs(pool),
+		WithTimeout(10*time.Second),
+		WithUserAgent("twittergo-test"))
+	if err != nil {
+		t.Fatalf("NewClientWithOptions returned error: %v", err)
+	}
+	if c.Host != host || c.UploadHost != "upload.example.com" || c.StreamHost != "stream.example.com" {
+		t.Errorf("Unexpected hosts %v %v %v", c.Host, c.UploadHost, c.StreamHost)
+	}
+	if c.OAuth.RequestURL != server.URL+"/oauth/request_token" {
+		t.Errorf("Unexpected request token URL %v", c.OAuth.RequestURL)
+	}
+	if c.HttpClient.Timeout != 10*time.Second {
+		t.Errorf("Unexpected timeout %v", c.HttpClient.Timeout)
+	}
+	req, _ := http.NewRequest("GET", "/1.1/account/verify_credentials.json", nil)
+	if _, err = c.SendRequest(req); err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	if agent != "twittergo-test" {
+		t.Errorf("Unexpected user agent %v", agent)
+	}
+
+	// The test server's certificate is not trusted by default.
+	if c, err = NewClientWithOptions(config, user, WithAPIHost(host)); err != nil {
+		t.Fatalf("NewClientWithOptions returned error: %v", err)
+	}
+	req, _ = http.NewRequest("GET", "/1.1/account/verify_credentials.json", nil)
+	if _, err = c.SendRequest(req); err == nil {
+		t.Errorf("Expected certificate error without root CAs")
+	}
+}
+
+func TestClientOptionTransports(t *testing.T) {
+	var (
+		proxy, _ = url.Parse("socks5://localhost:1080")
+		req, _   = http.NewRequest("GET", "https://api.twitter.com/", nil)
+	)
+	c, err := NewClientWithOptions(nil, nil, WithProxy(proxy))
+	if err != nil {
+		t.Fatalf("NewClientWithOptions returned error: %v", err)
+	}
+	if got, _ := c.HttpClient.Transport.(*http.Transport).Proxy(req); got != proxy {
+		t.Errorf("Expected SOCKS5 proxy, got %v", got)
+	}
+	if c, _ = NewClientWithOptions(nil, nil); c.HttpClient.Transport.(*http.Transport).Proxy != nil {
+		t.Errorf("Expected no proxy by default")
+	}
+
+	var mine = &http.Client{}
+	if c, err = NewClientWithOptions(nil, nil, WithHTTPClient(mine), WithTimeout(time.Second)); err != nil {
+		t.Fatalf("NewClientWithOptions returned error: %v", err)
+	}
+	if c.HttpClient.Timeout != time.Second || mine.Timeout != 0 {
+		t.Errorf("Expected a copy of the supplied client with the timeout set")
+	}
+
+	var errorCases = [][]ClientOption{
+		{WithHTTPClient(mine), WithProxy(proxy)},
+		{WithTransport(http.DefaultTransport), WithRootCAs(x509.NewCertPool())},
+		{WithHTTPClient(mine), WithTransport(http.DefaultTransport)},
+		{WithProxy(&url.URL{Scheme: "ftp", Host: "localhost"})},
+	}
+	for i, opts := range errorCases {
+		if _, err = NewClientWithOptions(nil, nil, opts...); err == nil {
+			t.Errorf("Expected error for case %v", i)
+		}
+	}
+}
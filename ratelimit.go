@@ -0,0 +1,180 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Preflight determines what a RateLimitTracker does with a request to an
+// endpoint whose rate limit is known to be exhausted.
+type Preflight int
+
+const (
+	// Send the request anyway and let the API reject it.
+	PREFLIGHT_NONE Preflight = iota
+	// Fail immediately with a RateLimitError.
+	PREFLIGHT_REFUSE
+	// Wait for the limit to reset, up to the tracker's MaxDelay.
+	PREFLIGHT_DELAY
+)
+
+const (
+	IDENTITY_APP  = "app"
+	IDENTITY_NONE = "none"
+)
+
+// Returns the endpoint template for a request path, which is the path with
+// any numeric segments after the API version replaced by ":id", e.g.
+// /1.1/statuses/show/123.json becomes /1.1/statuses/show/:id.json
+func EndpointPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if i < 2 {
+			continue // Leading slash and API version.
+		}
+		var (
+			name = strings.TrimSuffix(part, ".json")
+			ext  = part[len(name):]
+		)
+		if name != "" && strings.Trim(name, "0123456789") == "" {
+			parts[i] = ":id" + ext
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+type rateLimitKey struct {
+	identity string
+	path     string
+}
+
+// RateLimitTracker records the most recent rate limit headers seen for each
+// endpoint, separately for each set of credentials, so that callers can
+// check how many requests are left before sending one.
+// It is safe for concurrent use.
+type RateLimitTracker struct {
+	// Preflight controls how requests to exhausted endpoints are handled.
+	Preflight Preflight
+	// MaxDelay is the longest a PREFLIGHT_DELAY tracker will hold a request.
+	// Requests which would need to wait longer fail with a RateLimitError.
+	// Zero means there is no limit.
+	MaxDelay time.Duration
+
+	mu     sync.Mutex
+	limits map[rateLimitKey]RateLimitError
+}
+
+// Creates a tracker which records limits but never holds requests.
+func NewRateLimitTracker() *RateLimitTracker {
+	return &RateLimitTracker{
+		limits: map[rateLimitKey]RateLimitError{},
+	}
+}
+
+// Records the rate limit state for the endpoint at path when accessed with
+// the supplied identity.  See Client.Identity.
+func (t *RateLimitTracker) Update(identity string, path string, r RateLimitResponse) {
+	if !r.HasRateLimit() {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.limits == nil {
+		t.limits = map[rateLimitKey]RateLimitError{}
+	}
+	t.limits[rateLimitKey{identity, EndpointPath(path)}] = RateLimitError{
+		Limit:     r.RateLimit(),
+		Remaining: r.RateLimitRemaining(),
+		Reset:     r.RateLimitReset(),
+	}
+}
+
+// Returns the last recorded rate limit for the endpoint at path.
+// The boolean is false if no limit has been recorded.
+func (t *RateLimitTracker) Get(identity string, path string) (limit RateLimitError, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	limit, ok = t.limits[rateLimitKey{identity, EndpointPath(path)}]
+	return
+}
+
+// Returns how many requests remain for the endpoint at path.  If the last
+// recorded limit has since reset, the full limit is assumed to be available.
+// The boolean is false if no limit has been recorded.
+func (t *RateLimitTracker) Remaining(identity string, path string) (remaining uint32, ok bool) {
+	var limit RateLimitError
+	if limit, ok = t.Get(identity, path); !ok {
+		return
+	}
+	if !time.Now().Before(limit.Reset) {
+		return limit.Limit, true
+	}
+	return limit.Remaining, true
+}
+
+// Returns when the limit for the endpoint at path resets, or the zero time
+// if no limit has been recorded.
+func (t *RateLimitTracker) ResetAt(identity string, path string) time.Time {
+	limit, _ := t.Get(identity, path)
+	return limit.Reset
+}
+
+// Returns how long a request to path must wait before the limit resets, or
+// zero if the endpoint is not known to be exhausted.
+func (t *RateLimitTracker) Wait(identity string, path string) time.Duration {
+	limit, ok := t.Get(identity, path)
+	if !ok || limit.Remaining > 0 {
+		return 0
+	}
+	if wait := time.Until(limit.Reset); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// Applies the tracker's Preflight setting to a request which is about to be
+// sent, either returning a RateLimitError or waiting for the limit to reset.
+func (t *RateLimitTracker) preflight(ctx context.Context, identity string, path string) (err error) {
+	if t.Preflight == PREFLIGHT_NONE {
+		return
+	}
+	wait := t.Wait(identity, path)
+	if wait == 0 {
+		return
+	}
+	if t.Preflight == PREFLIGHT_REFUSE || (t.MaxDelay > 0 && wait > t.MaxDelay) {
+		limit, _ := t.Get(identity, path)
+		return limit
+	}
+	return sleepContext(ctx, wait)
+}
+
+// Records whichever rate limit headers are present on resp.
+func (t *RateLimitTracker) record(identity string, path string, resp *APIResponse) {
+	switch {
+	case resp.HasRateLimit():
+		t.Update(identity, path, resp)
+	case resp.HasMediaRateLimit():
+		t.Update(identity, path, RateLimitError{
+			Limit:     resp.MediaRateLimit(),
+			Remaining: resp.MediaRateLimitRemaining(),
+			Reset:     resp.MediaRateLimitReset(),
+		})
+	}
+}
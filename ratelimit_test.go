@@ -0,0 +1,147 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEndpointPath(t *testing.T) {
+	var cases = map[string]string{
+		"/1.1/statuses/user_timeline.json": "/1.1/statuses/user_timeline.json",
+		"/1.1/statuses/show/123.json":      "/1.1/statuses/show/:id.json",
+		"/1.1/statuses/retweet/456":        "/1.1/statuses/retweet/:id",
+		"/2/users/789/bookmarks":           "/2/users/:id/bookmarks",
+	}
+	for in, expected := range cases {
+		if out := EndpointPath(in); out != expected {
+			t.Errorf("EndpointPath(%v) returned %v, expected %v", in, out, expected)
+		}
+	}
+}
+
+func TestRateLimitTrackerRecordsResponses(t *testing.T) {
+	var (
+		reset  = time.Now().Add(time.Hour).Truncate(time.Second)
+		path   = "/1.1/statuses/user_timeline.json"
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(H_LIMIT, "900")
+			w.Header().Set(H_LIMIT_REMAIN, "899")
+			w.Header().Set(H_LIMIT_RESET, fmt.Sprintf("%v", reset.Unix()))
+			fmt.Fprint(w, `[]`)
+		}))
+		c         = getTestClient(server)
+		req       *http.Request
+		err       error
+		remaining uint32
+		ok        bool
+	)
+	defer server.Close()
+	if _, ok = c.RateLimitRemaining(path); ok {
+		t.Fatalf("Expected no limit to be recorded before sending a request")
+	}
+	req, _ = http.NewRequest("GET", server.URL+path+"?screen_name=kurrik", nil)
+	if _, err = c.SendRequest(req); err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	if remaining, ok = c.RateLimitRemaining(path); !ok || remaining != 899 {
+		t.Errorf("Expected 899 remaining, got %v (%v)", remaining, ok)
+	}
+	if !c.RateLimitResetAt(path).Equal(reset) {
+		t.Errorf("Expected reset at %v, got %v", reset, c.RateLimitResetAt(path))
+	}
+	if _, ok = c.RateLimits.Remaining(IDENTITY_APP, path); ok {
+		t.Errorf("Limits for user credentials should not apply to app auth")
+	}
+}
+
+func TestRateLimitTrackerExpiredLimit(t *testing.T) {
+	var (
+		tracker = NewRateLimitTracker()
+		path    = "/1.1/search/tweets.json"
+	)
+	tracker.Update(IDENTITY_APP, path, RateLimitError{
+		Limit:     450,
+		Remaining: 0,
+		Reset:     time.Now().Add(-time.Minute),
+	})
+	if remaining, _ := tracker.Remaining(IDENTITY_APP, path); remaining != 450 {
+		t.Errorf("Expected full limit after reset, got %v", remaining)
+	}
+	if wait := tracker.Wait(IDENTITY_APP, path); wait != 0 {
+		t.Errorf("Expected no wait after reset, got %v", wait)
+	}
+}
+
+func TestRateLimitTrackerRefusesExhausted(t *testing.T) {
+	var (
+		requests int
+		path     = "/1.1/statuses/show/123.json"
+		server   = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			fmt.Fprint(w, `{}`)
+		}))
+		c   = getTestClient(server)
+		req *http.Request
+		err error
+		ok  bool
+	)
+	defer server.Close()
+	c.RateLimits.Preflight = PREFLIGHT_REFUSE
+	c.RateLimits.Update(c.Identity(), "/1.1/statuses/show/456.json", RateLimitError{
+		Limit:     900,
+		Remaining: 0,
+		Reset:     time.Now().Add(time.Hour),
+	})
+	req, _ = http.NewRequest("GET", server.URL+path, nil)
+	_, err = c.SendRequest(req)
+	if _, ok = err.(RateLimitError); !ok {
+		t.Fatalf("Expected a RateLimitError, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Expected request to be refused without being sent")
+	}
+}
+
+func TestRateLimitTrackerDelaysExhausted(t *testing.T) {
+	var (
+		tracker = NewRateLimitTracker()
+		path    = "/1.1/friends/ids.json"
+		err     error
+	)
+	tracker.Preflight = PREFLIGHT_DELAY
+	tracker.MaxDelay = time.Second
+	tracker.Update(IDENTITY_APP, path, RateLimitError{
+		Limit:     15,
+		Remaining: 0,
+		Reset:     time.Now().Add(time.Hour),
+	})
+	if _, ok := tracker.preflight(context.Background(), IDENTITY_APP, path).(RateLimitError); !ok {
+		t.Errorf("Expected a RateLimitError when the wait exceeds MaxDelay")
+	}
+	tracker.Update(IDENTITY_APP, path, RateLimitError{
+		Limit:     15,
+		Remaining: 0,
+		Reset:     time.Now().Add(10 * time.Millisecond),
+	})
+	if err = tracker.preflight(context.Background(), IDENTITY_APP, path); err != nil {
+		t.Errorf("Expected request to be delayed, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how SendRequest handles rate limited (HTTP 429)
+// responses.  When a policy is set on a Client, requests which are rate
+// limited are held until the limit resets and then re-signed and re-sent.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of times a request will be sent,
+	// including the first attempt.  Values less than 2 disable retries.
+	MaxAttempts int
+	// MaxWait is the longest SendRequest will wait for a limit to reset.
+	// If the reset is further away, the rate limited response is returned.
+	// Zero means there is no limit.
+	MaxWait time.Duration
+	// Jitter is the upper bound of a random duration added to each wait,
+	// which keeps many clients from retrying at exactly the same moment.
+	Jitter time.Duration
+	// MinWait is the shortest time to wait before sending a rate limited
+	// request again, used when the reset time is missing or has passed.
+	// Iterators which wait on rate limits double it with each attempt.
+	MinWait time.Duration
+}
+
+// The minimum wait used by iterators when the policy does not set MinWait.
+const defaultMinWait = time.Second
+
+// Returns a RetryPolicy with reasonable defaults for most applications.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		MaxWait:     15 * time.Minute,
+		Jitter:      time.Second,
+		MinWait:     time.Second,
+	}
+}
+
+type noRetryKey struct{}
+
+// Returns a context under which SendRequestContext does not apply the
+// client's RetryPolicy, for callers which retry rate limited requests
+// themselves.
+func withoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// Returns the RetryPolicy which applies to requests sent with ctx.
+func (c *Client) retryPolicy(ctx context.Context) *RetryPolicy {
+	if ctx.Value(noRetryKey{}) != nil {
+		return nil
+	}
+	return c.Retry
+}
+
+// Determines whether the request which produced resp should be sent again
+// and, if so, how long to wait before doing so.
+func (p *RetryPolicy) next(attempt int, resp *APIResponse) (wait time.Duration, retry bool) {
+	if p == nil || resp == nil || resp.StatusCode != STATUS_LIMIT {
+		return
+	}
+	if attempt >= p.MaxAttempts {
+		return
+	}
+	var reset time.Time
+	switch {
+	case resp.Header.Get(H_LIMIT_RESET) != "":
+		reset = resp.RateLimitReset()
+	case resp.Header.Get(H_MEDIA_LIMIT_RESET) != "":
+		reset = resp.MediaRateLimitReset()
+	default:
+		return // No way to tell when it is safe to try again.
+	}
+	if wait = time.Until(reset); wait < p.MinWait {
+		wait = p.MinWait
+	}
+	if p.MaxWait > 0 && wait > p.MaxWait {
+		return 0, false
+	}
+	if p.Jitter > 0 {
+		wait += time.Duration(rand.Int63n(int64(p.Jitter)))
+	}
+	return wait, true
+}
+
+// Determines whether a call which returned limit after attempt tries, having
+// already waited for waited in total, should be made again and, if so, how
+// long to wait before doing so.  Unlike next, a wait is always imposed even
+// if the limit has no reset time, and MaxWait bounds the total time waited.
+func (p *RetryPolicy) nextLimited(attempt int, waited time.Duration, limit RateLimitError) (wait time.Duration, retry bool) {
+	if p == nil || attempt >= p.MaxAttempts {
+		return
+	}
+	backoff := p.MinWait
+	if backoff <= 0 {
+		backoff = defaultMinWait
+	}
+	backoff <<= uint(attempt - 1)
+	if wait = time.Until(limit.Reset); wait < backoff {
+		wait = backoff
+	}
+	if p.Jitter > 0 {
+		wait += time.Duration(rand.Int63n(int64(p.Jitter)))
+	}
+	if p.MaxWait > 0 && waited+wait > p.MaxWait {
+		return 0, false
+	}
+	return wait, true
+}
+
+// Buffers the request body so that it may be replayed if the request is
+// sent more than once.
+func bufferBody(req *http.Request) (err error) {
+	var b []byte
+	if req.Body == nil || req.GetBody != nil {
+		return
+	}
+	if b, err = ioutil.ReadAll(req.Body); err != nil {
+		return
+	}
+	req.Body.Close()
+	req.GetBody = func() (body io.ReadCloser, err error) {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return
+}
+
+// Resets the request body to its original state before a retry.
+func rewindBody(req *http.Request) (err error) {
+	if req.Body == nil || req.GetBody == nil {
+		return
+	}
+	req.Body, err = req.GetBody()
+	return
+}
+
+// Reads and closes the body of a response which is about to be discarded
+// so that the underlying connection may be reused.
+func discardBody(resp *APIResponse) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+}
+
+// Waits for the supplied duration, returning early with the context's error
+// if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Returns a server which rate limits the first `limited` requests and then
+// responds normally, recording each request it receives.
+func getRateLimitServer(limited int, reset time.Time, received *[]*http.Request, bodies *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		*received = append(*received, r)
+		*bodies = append(*bodies, string(b))
+		if len(*received) <= limited {
+			w.Header().Set(H_LIMIT, "15")
+			w.Header().Set(H_LIMIT_REMAIN, "0")
+			w.Header().Set(H_LIMIT_RESET, fmt.Sprintf("%v", reset.Unix()))
+			w.WriteHeader(STATUS_LIMIT)
+			fmt.Fprint(w, `{"errors":[{"message":"Rate limit exceeded","code":88}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"id_str":"1234"}`)
+	}))
+}
+
+func TestRetryAfterRateLimit(t *testing.T) {
+	var (
+		received []*http.Request
+		bodies   []string
+		server   = getRateLimitServer(1, time.Now().Add(-time.Second), &received, &bodies)
+		c        = getTestClient(server)
+		data     = url.Values{"status": []string{"Hello"}}
+		req      *http.Request
+		resp     *APIResponse
+		tweet    = &Tweet{}
+		err      error
+	)
+	defer server.Close()
+	c.Retry = &RetryPolicy{MaxAttempts: 3}
+	req, _ = http.NewRequest("POST", server.URL+"/1.1/statuses/update.json", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if resp, err = c.SendRequest(req); err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	if err = resp.Parse(tweet); err != nil {
+		t.Fatalf("Expected retried request to succeed, got %v", err)
+	}
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 requests, got %v", len(received))
+	}
+	if received[0].Header.Get("Authorization") == received[1].Header.Get("Authorization") {
+		t.Errorf("Expected retried request to be re-signed")
+	}
+	if bodies[0] != "status=Hello" || bodies[1] != "status=Hello" {
+		t.Errorf("Expected request body to be replayed, got %v", bodies)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	var (
+		received []*http.Request
+		bodies   []string
+		server   = getRateLimitServer(5, time.Now().Add(-time.Second), &received, &bodies)
+		c        = getTestClient(server)
+		req      *http.Request
+		resp     *APIResponse
+		err      error
+		ok       bool
+	)
+	defer server.Close()
+	c.Retry = &RetryPolicy{MaxAttempts: 2}
+	req, _ = http.NewRequest("GET", server.URL+"/1.1/statuses/show.json?id=1234", nil)
+	if resp, err = c.SendRequest(req); err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 requests, got %v", len(received))
+	}
+	if _, ok = resp.Parse(&Tweet{}).(RateLimitError); !ok {
+		t.Errorf("Expected a RateLimitError once retries were exhausted")
+	}
+}
+
+func TestRetrySkippedWhenResetTooFar(t *testing.T) {
+	var (
+		received []*http.Request
+		bodies   []string
+		server   = getRateLimitServer(1, time.Now().Add(time.Hour), &received, &bodies)
+		c        = getTestClient(server)
+		req      *http.Request
+		resp     *APIResponse
+		err      error
+	)
+	defer server.Close()
+	c.Retry = &RetryPolicy{MaxAttempts: 3, MaxWait: time.Minute}
+	req, _ = http.NewRequest("GET", server.URL+"/1.1/statuses/show.json?id=1234", nil)
+	if resp, err = c.SendRequest(req); err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	if len(received) != 1 {
+		t.Errorf("Expected a single request, got %v", len(received))
+	}
+	if resp.StatusCode != STATUS_LIMIT {
+		t.Errorf("Expected rate limited response, got %v", resp.StatusCode)
+	}
+}
+
+func TestNoRetryWithoutPolicy(t *testing.T) {
+	var (
+		received []*http.Request
+		bodies   []string
+		server   = getRateLimitServer(1, time.Now().Add(-time.Second), &received, &bodies)
+		c        = getTestClient(server)
+		req      *http.Request
+		err      error
+	)
+	defer server.Close()
+	req, _ = http.NewRequest("GET", server.URL+"/1.1/statuses/show.json?id=1234", nil)
+	if _, err = c.SendRequest(req); err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	if len(received) != 1 {
+		t.Errorf("Expected a single request, got %v", len(received))
+	}
+}
+
+func TestRetryWaitHonorsContext(t *testing.T) {
+	var (
+		received    []*http.Request
+		bodies      []string
+		server      = getRateLimitServer(1, time.Now().Add(time.Hour), &received, &bodies)
+		c           = getTestClient(server)
+		req         *http.Request
+		err         error
+		ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	)
+	defer server.Close()
+	defer cancel()
+	c.Retry = &RetryPolicy{MaxAttempts: 3}
+	req, _ = http.NewRequest("GET", server.URL+"/1.1/statuses/show.json?id=1234", nil)
+	if _, err = c.SendRequestContext(ctx, req); err != context.DeadlineExceeded {
+		t.Fatalf("Expected the wait to be cut short by the context, got %v", err)
+	}
+	if len(received) != 1 {
+		t.Errorf("Expected a single request, got %v", len(received))
+	}
+}
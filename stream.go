@@ -0,0 +1,324 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	PATH_STREAM_FILTER = "/1.1/statuses/filter.json"
+	PATH_STREAM_SAMPLE = "/1.1/statuses/sample.json"
+)
+
+const (
+	STATUS_ENHANCE_YOUR_CALM = 420
+)
+
+// A message delivered by a Stream.  One of Tweet, StreamDelete,
+// StreamLimit, StreamDisconnect, StreamWarning or StreamUnknown.
+type StreamMessage interface{}
+
+// Notice that a Tweet has been deleted.
+type StreamDelete map[string]interface{}
+
+func (d StreamDelete) IdStr() string {
+	return stringValue(mapValue(d, "status"), "id_str")
+}
+
+func (d StreamDelete) UserIdStr() string {
+	return stringValue(mapValue(d, "status"), "user_id_str")
+}
+
+// Notice that more Tweets matched a filter than could be delivered.
+type StreamLimit map[string]interface{}
+
+// The number of undelivered Tweets since the connection was opened.
+func (l StreamLimit) Track() int64 {
+	return int64Value(l, "track")
+}
+
+// Notice that the stream is about to be closed by the server.
+type StreamDisconnect map[string]interface{}
+
+func (d StreamDisconnect) Code() int64 {
+	return int64Value(d, "code")
+}
+
+func (d StreamDisconnect) Reason() string {
+	return stringValue(d, "reason")
+}
+
+// Notice that the client is falling behind and may be disconnected.
+type StreamWarning map[string]interface{}
+
+func (w StreamWarning) Code() string {
+	return stringValue(w, "code")
+}
+
+func (w StreamWarning) Message() string {
+	return stringValue(w, "message")
+}
+
+func (w StreamWarning) PercentFull() int64 {
+	return int64Value(w, "percent_full")
+}
+
+// Any message which is not a Tweet or a known control message.
+type StreamUnknown map[string]interface{}
+
+// Stream consumes a long-lived streaming endpoint, decoding each message
+// and reconnecting with back-off when the connection fails.
+//
+// Back-off follows Twitter's guidelines: linear for network errors,
+// exponential for HTTP errors, and exponential from a longer starting point
+// for HTTP 420 and 429 responses.
+type Stream struct {
+	Client *Client
+	Method string
+	URL    string
+	Params url.Values
+
+	// Linear back-off step and limit for network errors.
+	NetworkBackoff    time.Duration
+	NetworkBackoffMax time.Duration
+	// Exponential back-off start and limit for HTTP errors.
+	HTTPBackoff    time.Duration
+	HTTPBackoffMax time.Duration
+	// Exponential back-off start and limit for rate limited connections.
+	RateLimitBackoff    time.Duration
+	RateLimitBackoffMax time.Duration
+	// The connection is considered dead if nothing, including keep-alive
+	// newlines, is received for this long.
+	StallTimeout time.Duration
+
+	err error
+	// Replaces sleepContext between connection attempts in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Creates a stream for the endpoint at path on the client's stream host.
+// GET parameters are sent in the query string, others in a form body.
+func NewStream(c *Client, method string, path string, params url.Values) *Stream {
+	return &Stream{
+		Client:              c,
+		Method:              method,
+		URL:                 fmt.Sprintf("https://%v%v", c.StreamHost, path),
+		Params:              params,
+		NetworkBackoff:      250 * time.Millisecond,
+		NetworkBackoffMax:   16 * time.Second,
+		HTTPBackoff:         5 * time.Second,
+		HTTPBackoffMax:      320 * time.Second,
+		RateLimitBackoff:    time.Minute,
+		RateLimitBackoffMax: 16 * time.Minute,
+		StallTimeout:        90 * time.Second,
+	}
+}
+
+// Connects to the stream and delivers messages on the returned channel
+// until ctx is done or the connection fails with an error which cannot be
+// resolved by reconnecting, such as rejected or missing credentials.  The channel is closed when
+// the stream stops; call Err to find out why.
+func (s *Stream) Run(ctx context.Context) <-chan StreamMessage {
+	var messages = make(chan StreamMessage)
+	go func() {
+		defer close(messages)
+		s.err = s.run(ctx, messages)
+	}()
+	return messages
+}
+
+// Returns the error which stopped the stream, or nil if it was stopped by
+// cancelling its context.  Only valid once the message channel is closed.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+func (s *Stream) run(ctx context.Context, messages chan<- StreamMessage) (err error) {
+	var (
+		network   time.Duration
+		httperr   time.Duration
+		ratelimit time.Duration
+		wait      time.Duration
+		resp      *APIResponse
+	)
+	for {
+		resp, err = s.connect(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case isRateLimitError(err):
+			ratelimit = increase(ratelimit*2, s.RateLimitBackoffMax)
+			if ratelimit == 0 {
+				ratelimit = s.RateLimitBackoff
+			}
+			wait = ratelimit
+		case isNetworkError(err):
+			network = increase(network+s.NetworkBackoff, s.NetworkBackoffMax)
+			wait = network
+		case err != nil:
+			return
+		case resp.StatusCode == STATUS_OK:
+			network, httperr, ratelimit = 0, 0, 0
+			s.read(ctx, resp, messages)
+			if ctx.Err() != nil {
+				return nil
+			}
+			network = s.NetworkBackoff
+			wait = network
+		case resp.StatusCode == STATUS_ENHANCE_YOUR_CALM || resp.StatusCode == STATUS_LIMIT:
+			discardBody(resp)
+			ratelimit = increase(ratelimit*2, s.RateLimitBackoffMax)
+			if ratelimit == 0 {
+				ratelimit = s.RateLimitBackoff
+			}
+			wait = ratelimit
+		case isFatalStreamStatus(resp.StatusCode):
+			if err = resp.ParseContext(ctx, nil); err == nil {
+				err = NewResponseError(resp.StatusCode, "")
+			}
+			return
+		default:
+			discardBody(resp)
+			httperr = increase(httperr*2, s.HTTPBackoffMax)
+			if httperr == 0 {
+				httperr = s.HTTPBackoff
+			}
+			wait = httperr
+		}
+		if err = s.pause(ctx, wait); err != nil {
+			return nil
+		}
+	}
+}
+
+// Waits before the next connection attempt.
+func (s *Stream) pause(ctx context.Context, d time.Duration) error {
+	if s.sleep != nil {
+		return s.sleep(ctx, d)
+	}
+	return sleepContext(ctx, d)
+}
+
+// Reports whether err came from the network or transport, rather than from
+// building, authenticating or rate limiting the request.
+func isNetworkError(err error) bool {
+	var (
+		uerr *url.Error
+		nerr net.Error
+	)
+	if errors.As(err, &uerr) && uerr.Op == "parse" {
+		return false // A malformed URL, which retrying will not fix.
+	}
+	return errors.As(err, &uerr) || errors.As(err, &nerr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Reports whether the request was refused by the client's RateLimitTracker.
+func isRateLimitError(err error) bool {
+	_, ok := err.(RateLimitError)
+	return ok
+}
+
+// Returns d, or max if d is larger.
+func increase(d time.Duration, max time.Duration) time.Duration {
+	if d > max {
+		return max
+	}
+	return d
+}
+
+// Statuses which indicate a problem with the request or credentials, which
+// reconnecting will not fix.
+func isFatalStreamStatus(code int) bool {
+	switch code {
+	case STATUS_UNAUTHORIZED, STATUS_FORBIDDEN, STATUS_NOTFOUND, 406, 413, 416:
+		return true
+	}
+	return false
+}
+
+func (s *Stream) connect(ctx context.Context) (resp *APIResponse, err error) {
+	var req *http.Request
+	if req, err = newParamsRequest(ctx, s.Method, s.URL, s.Params); err != nil {
+		return
+	}
+	return s.Client.SendRequestContext(ctx, req)
+}
+
+// Reads messages from a connected stream until the connection ends.
+func (s *Stream) read(ctx context.Context, resp *APIResponse, messages chan<- StreamMessage) {
+	var (
+		reader = bufio.NewReader(resp.Body)
+		stall  = time.AfterFunc(s.StallTimeout, func() { resp.Body.Close() })
+		line   []byte
+		err    error
+	)
+	defer stall.Stop()
+	defer resp.Body.Close()
+	for {
+		line, err = reader.ReadBytes('\n')
+		stall.Reset(s.StallTimeout)
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			select {
+			case messages <- decodeStreamMessage(line, s.Client.UseNumber):
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Decodes a single line from a stream into a Tweet or control message.
+func decodeStreamMessage(line []byte, useNumber bool) StreamMessage {
+	var (
+		m = map[string]interface{}{}
+		d = json.NewDecoder(bytes.NewReader(line))
+	)
+	if useNumber {
+		d.UseNumber()
+	}
+	if err := d.Decode(&m); err != nil {
+		return StreamUnknown{"raw": string(line)}
+	}
+	if v, ok := m["delete"].(map[string]interface{}); ok {
+		return StreamDelete(v)
+	}
+	if v, ok := m["limit"].(map[string]interface{}); ok {
+		return StreamLimit(v)
+	}
+	if v, ok := m["disconnect"].(map[string]interface{}); ok {
+		return StreamDisconnect(v)
+	}
+	if v, ok := m["warning"].(map[string]interface{}); ok {
+		return StreamWarning(v)
+	}
+	if _, ok := m["id_str"]; ok {
+		return Tweet(m)
+	}
+	return StreamUnknown(m)
+}
@@ -0,0 +1,286 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Shrinks back-off durations so tests reconnect quickly.
+func getTestStream(c *Client, params url.Values) *Stream {
+	s := NewStream(c, "POST", PATH_STREAM_FILTER, params)
+	s.NetworkBackoff = time.Millisecond
+	s.NetworkBackoffMax = 5 * time.Millisecond
+	s.HTTPBackoff = time.Millisecond
+	s.HTTPBackoffMax = 5 * time.Millisecond
+	s.RateLimitBackoff = time.Millisecond
+	s.RateLimitBackoffMax = 5 * time.Millisecond
+	return s
+}
+
+func TestStreamDecodesMessages(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		connections int
+		track       string
+		server      = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			connections++
+			var n = connections
+			track = r.FormValue("track")
+			mu.Unlock()
+			switch n {
+			case 1:
+				w.WriteHeader(503)
+				return
+			case 2:
+				w.WriteHeader(STATUS_ENHANCE_YOUR_CALM)
+				return
+			}
+			w.(http.Flusher).Flush()
+			fmt.Fprint(w, "\r\n")
+			fmt.Fprint(w, `{"id_str":"1","text":"golang"}`+"\r\n")
+			fmt.Fprint(w, "\r\n")
+			fmt.Fprint(w, `{"delete":{"status":{"id_str":"2","user_id_str":"3"}}}`+"\r\n")
+			fmt.Fprint(w, `{"limit":{"track":1234}}`+"\r\n")
+			fmt.Fprint(w, `{"warning":{"code":"FALLING_BEHIND","percent_full":60}}`+"\r\n")
+			fmt.Fprint(w, `{"disconnect":{"code":7,"reason":"admin logout"}}`+"\r\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		c           = getTestClient(server)
+		ctx, cancel = context.WithCancel(context.Background())
+		stream      *Stream
+		received    []StreamMessage
+	)
+	defer server.Close()
+	defer cancel()
+	c.StreamHost = server.Listener.Addr().String()
+	stream = getTestStream(c, url.Values{"track": []string{"golang"}})
+	for msg := range stream.Run(ctx) {
+		received = append(received, msg)
+		if len(received) == 5 {
+			cancel()
+		}
+	}
+	if err := stream.Err(); err != nil {
+		t.Errorf("Expected a clean stop, got %v", err)
+	}
+	if track != "golang" {
+		t.Errorf("Expected filter parameters to be sent, got %v", track)
+	}
+	if len(received) != 5 {
+		t.Fatalf("Expected 5 messages, got %v", received)
+	}
+	if tweet, ok := received[0].(Tweet); !ok || tweet.Text() != "golang" {
+		t.Errorf("Expected a Tweet, got %v", received[0])
+	}
+	if del, ok := received[1].(StreamDelete); !ok || del.IdStr() != "2" || del.UserIdStr() != "3" {
+		t.Errorf("Expected a delete, got %v", received[1])
+	}
+	if limit, ok := received[2].(StreamLimit); !ok || limit.Track() != 1234 {
+		t.Errorf("Expected a limit, got %v", received[2])
+	}
+	if warning, ok := received[3].(StreamWarning); !ok || warning.PercentFull() != 60 {
+		t.Errorf("Expected a warning, got %v", received[3])
+	}
+	if disconnect, ok := received[4].(StreamDisconnect); !ok || disconnect.Reason() != "admin logout" {
+		t.Errorf("Expected a disconnect, got %v", received[4])
+	}
+}
+
+func TestStreamReconnectsAfterClose(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		connections int
+		server      = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			connections++
+			var n = connections
+			mu.Unlock()
+			fmt.Fprintf(w, `{"id_str":"%v"}`+"\r\n", n)
+		}))
+		c           = getTestClient(server)
+		ctx, cancel = context.WithCancel(context.Background())
+		ids         []string
+	)
+	defer server.Close()
+	defer cancel()
+	c.StreamHost = server.Listener.Addr().String()
+	for msg := range getTestStream(c, nil).Run(ctx) {
+		ids = append(ids, msg.(Tweet).IdStr())
+		if len(ids) == 3 {
+			cancel()
+		}
+	}
+	if len(ids) != 3 || ids[0] != "1" || ids[2] != "3" {
+		t.Errorf("Expected one message per connection, got %v", ids)
+	}
+}
+
+func TestStreamStopsOnUnauthorized(t *testing.T) {
+	var (
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(STATUS_UNAUTHORIZED)
+			fmt.Fprint(w, `{"errors":[{"code":32,"message":"Could not authenticate you."}]}`)
+		}))
+		c      = getTestClient(server)
+		stream *Stream
+	)
+	defer server.Close()
+	c.StreamHost = server.Listener.Addr().String()
+	stream = getTestStream(c, nil)
+	for msg := range stream.Run(context.Background()) {
+		t.Errorf("Unexpected message %v", msg)
+	}
+	if _, ok := stream.Err().(Errors); !ok {
+		t.Errorf("Expected Errors, got %v", stream.Err())
+	}
+}
+
+func TestStreamStallTimeout(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		connections int
+		server      = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			connections++
+			mu.Unlock()
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		c           = getTestClient(server)
+		ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+		stream      *Stream
+	)
+	defer server.Close()
+	defer cancel()
+	c.StreamHost = server.Listener.Addr().String()
+	stream = getTestStream(c, nil)
+	stream.StallTimeout = 20 * time.Millisecond
+	for range stream.Run(ctx) {
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if connections < 2 {
+		t.Errorf("Expected stalled connections to be reopened, got %v connections", connections)
+	}
+}
+
+// Runs the stream until it has waited n times, returning the waits.
+func getStreamBackoff(t *testing.T, s *Stream, n int) (waits []time.Duration) {
+	var ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	s.sleep = func(ctx context.Context, d time.Duration) error {
+		if waits = append(waits, d); len(waits) == n {
+			cancel()
+		}
+		return ctx.Err()
+	}
+	for range s.Run(ctx) {
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("Expected the stream to keep reconnecting, got %v", err)
+	}
+	return
+}
+
+func TestStreamNetworkBackoff(t *testing.T) {
+	var (
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		c      = getTestClient(server)
+		stream *Stream
+	)
+	c.StreamHost = server.Listener.Addr().String()
+	server.Close()
+	stream = getTestStream(c, nil)
+	stream.NetworkBackoff = time.Millisecond
+	stream.NetworkBackoffMax = 3 * time.Millisecond
+	if waits := getStreamBackoff(t, stream, 5); fmt.Sprint(waits) != "[1ms 2ms 3ms 3ms 3ms]" {
+		t.Errorf("Expected linear back-off for network errors, got %v", waits)
+	}
+}
+
+func TestStreamHTTPBackoff(t *testing.T) {
+	var (
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(503)
+		}))
+		c      = getTestClient(server)
+		stream *Stream
+	)
+	defer server.Close()
+	c.StreamHost = server.Listener.Addr().String()
+	stream = getTestStream(c, nil)
+	stream.HTTPBackoff = time.Millisecond
+	stream.HTTPBackoffMax = 8 * time.Millisecond
+	if waits := getStreamBackoff(t, stream, 6); fmt.Sprint(waits) != "[1ms 2ms 4ms 8ms 8ms 8ms]" {
+		t.Errorf("Expected exponential back-off for HTTP errors, got %v", waits)
+	}
+}
+
+func TestStreamRateLimitBackoff(t *testing.T) {
+	for _, status := range []int{STATUS_ENHANCE_YOUR_CALM, STATUS_LIMIT} {
+		var (
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			}))
+			c = getTestClient(server)
+		)
+		c.StreamHost = server.Listener.Addr().String()
+		// The default schedule, which starts at a minute.
+		var stream = NewStream(c, "POST", PATH_STREAM_FILTER, nil)
+		if waits := getStreamBackoff(t, stream, 6); fmt.Sprint(waits) != "[1m0s 2m0s 4m0s 8m0s 16m0s 16m0s]" {
+			t.Errorf("Expected exponential back-off from a minute for %v, got %v", status, waits)
+		}
+		server.Close()
+	}
+}
+
+func TestStreamStopsOnAppTokenError(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+		server   = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests++
+			mu.Unlock()
+			w.WriteHeader(STATUS_FORBIDDEN)
+			fmt.Fprint(w, `{"errors":[{"code":99,"message":"Unable to verify your credentials"}]}`)
+		}))
+		c      = getHostTestClient(server)
+		stream *Stream
+	)
+	defer server.Close()
+	c.SetUser(nil)
+	c.StreamHost = server.Listener.Addr().String()
+	stream = getTestStream(c, nil)
+	for msg := range stream.Run(context.Background()) {
+		t.Errorf("Unexpected message %v", msg)
+	}
+	if errs, ok := stream.Err().(Errors); !ok || !errs.HasCode(99) {
+		t.Errorf("Expected the token error, got %v", stream.Err())
+	}
+	if requests != 1 {
+		t.Errorf("Expected a single token request, got %v", requests)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	PATH_LISTS_STATUSES = "/1.1/lists/statuses.json"
+)
+
+// Direction determines which way a TimelineIterator walks.
+type Direction int
+
+const (
+	// From the newest Tweets (or max_id) toward older ones.
+	DIRECTION_BACKWARD Direction = iota
+	// From since_id toward newer Tweets.
+	DIRECTION_FORWARD
+)
+
+// TimelineIterator pages through a timeline endpoint such as
+// statuses/user_timeline, statuses/home_timeline,
+// statuses/mentions_timeline or lists/statuses using max_id and since_id.
+// https://developer.twitter.com/en/docs/twitter-api/v1/tweets/timelines/guides/working-with-timelines
+//
+// Walking backward, each request sets max_id to one less than the smallest
+// ID seen so far, until an empty page is returned.  Walking forward, the
+// iterator collects everything newer than since_id without leaving gaps:
+// it walks backward from the newest Tweet down to since_id, then raises
+// since_id to the newest Tweet seen and repeats until nothing new is found.
+// Pages are always returned newest first, as the API returns them.
+type TimelineIterator struct {
+	Client    *Client
+	Path      string
+	Params    url.Values
+	Direction Direction
+	// Stop after this many Tweets.  Zero means no limit.
+	Limit int
+	// When walking backward, stop at the first Tweet created before Since.
+	Since time.Time
+	// If set, pages which are rate limited are requested again once the
+	// limit resets, as for CursorIterator.
+	WaitOnRateLimit bool
+
+	sinceId  uint64
+	maxId    uint64
+	roundMax uint64
+	count    int
+	done     bool
+}
+
+// Creates an iterator for the timeline endpoint at path.  A since_id or
+// max_id in params sets where the walk starts.
+func NewTimelineIterator(c *Client, path string, params url.Values) *TimelineIterator {
+	var it = &TimelineIterator{
+		Client: c,
+		Path:   path,
+		Params: url.Values{},
+	}
+	for k, v := range params {
+		it.Params[k] = v
+	}
+	it.sinceId, _ = strconv.ParseUint(it.Params.Get("since_id"), 10, 64)
+	it.maxId, _ = strconv.ParseUint(it.Params.Get("max_id"), 10, 64)
+	it.Params.Del("since_id")
+	it.Params.Del("max_id")
+	return it
+}
+
+// Returns true once there are no more pages to read.
+func (it *TimelineIterator) Done() bool {
+	return it.done
+}
+
+// Returns the next page of Tweets, or io.EOF once the walk is finished.
+func (it *TimelineIterator) Next(ctx context.Context) (page Timeline, err error) {
+	for !it.done {
+		if page, err = it.fetch(ctx); err != nil {
+			return
+		}
+		if it.Direction == DIRECTION_FORWARD {
+			page = it.advanceForward(page)
+		} else {
+			page = it.advanceBackward(page)
+		}
+		if len(page) > 0 {
+			if page = it.bound(page); len(page) > 0 {
+				return page, nil
+			}
+		}
+	}
+	return nil, io.EOF
+}
+
+func (it *TimelineIterator) fetch(ctx context.Context) (page Timeline, err error) {
+	var params = url.Values{}
+	for k, v := range it.Params {
+		params[k] = v
+	}
+	setId(params, "since_id", it.sinceId)
+	setId(params, "max_id", it.maxId)
+	err = it.Client.callWaiting(ctx, it.WaitOnRateLimit, "GET", it.Path, params, &page)
+	return
+}
+
+// Moves max_id below the page, finishing on an empty page.
+func (it *TimelineIterator) advanceBackward(page Timeline) Timeline {
+	if len(page) == 0 {
+		it.done = true
+		return page
+	}
+	min, _ := timelineBounds(page)
+	if min <= 1 {
+		it.done = true
+	}
+	it.maxId = min - 1
+	return page
+}
+
+// Walks backward through the current window above since_id, starting a
+// new window once it is exhausted.
+func (it *TimelineIterator) advanceForward(page Timeline) Timeline {
+	if len(page) == 0 {
+		if it.maxId == 0 {
+			it.done = true // Nothing newer than since_id.
+		} else {
+			it.sinceId, it.maxId, it.roundMax = it.roundMax, 0, 0
+		}
+		return page
+	}
+	min, max := timelineBounds(page)
+	if min == 0 {
+		it.done = true // No IDs to continue from.
+		return page
+	}
+	if max > it.roundMax {
+		it.roundMax = max
+	}
+	if min <= it.sinceId+1 {
+		it.sinceId, it.maxId, it.roundMax = it.roundMax, 0, 0
+	} else {
+		it.maxId = min - 1
+	}
+	return page
+}
+
+// Truncates the page to the iterator's count and time bounds.
+func (it *TimelineIterator) bound(page Timeline) Timeline {
+	if it.Direction == DIRECTION_BACKWARD && !it.Since.IsZero() {
+		for i, tweet := range page {
+			if tweet.CreatedAt().Before(it.Since) {
+				page = page[:i]
+				it.done = true
+				break
+			}
+		}
+	}
+	if it.Limit > 0 && it.count+len(page) >= it.Limit {
+		page = page[:it.Limit-it.count]
+		it.done = true
+	}
+	it.count += len(page)
+	return page
+}
+
+// Returns the smallest and largest Tweet IDs in a page, ignoring Tweets
+// without an ID.  Both are zero if no Tweet has an ID.
+func timelineBounds(page Timeline) (min uint64, max uint64) {
+	for _, tweet := range page {
+		id := tweet.Id()
+		if id == 0 {
+			continue
+		}
+		if min == 0 || id < min {
+			min = id
+		}
+		if id > max {
+			max = id
+		}
+	}
+	return
+}
+
+// SearchIterator follows SearchResults.NextQuery until a search has no more
+// results.
+type SearchIterator struct {
+	Client *Client
+	// Stop after this many Tweets.  Zero means no limit.
+	Limit int
+	// If set, pages which are rate limited are requested again once the
+	// limit resets, as for CursorIterator.
+	WaitOnRateLimit bool
+
+	next  url.Values
+	count int
+}
+
+// Creates an iterator starting with the search described by p.
+func NewSearchIterator(c *Client, p SearchParams) *SearchIterator {
+	return &SearchIterator{
+		Client: c,
+		next:   p.Values(),
+	}
+}
+
+// Returns true once there are no more pages to read.
+func (it *SearchIterator) Done() bool {
+	return it.next == nil
+}
+
+// Returns the next page of results, or io.EOF once the search is
+// exhausted.
+func (it *SearchIterator) Next(ctx context.Context) (results SearchResults, err error) {
+	if it.Done() {
+		return nil, io.EOF
+	}
+	results = SearchResults{}
+	if err = it.Client.callWaiting(ctx, it.WaitOnRateLimit, "GET", PATH_SEARCH_TWEETS, it.next, &results); err != nil {
+		return
+	}
+	var statuses = arrayValue(results, "statuses")
+	if next, nerr := results.NextQuery(); nerr != nil || len(statuses) == 0 {
+		it.next = nil
+	} else {
+		it.next = next
+	}
+	if it.Limit > 0 && it.count+len(statuses) >= it.Limit {
+		results["statuses"] = statuses[:it.Limit-it.count]
+		it.next = nil
+	}
+	it.count += len(arrayValue(results, "statuses"))
+	if len(statuses) == 0 {
+		return nil, io.EOF
+	}
+	return
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// IDs above 2^53 which cannot be represented exactly as float64 values.
+const timelineBase uint64 = 1002011200236892100
+
+// Returns a server which implements max_id, since_id and count over a
+// timeline of the supplied number of Tweets, one minute apart.
+func getTimelineServer(size int, queries *[]url.Values) *httptest.Server {
+	var now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			q        = r.URL.Query()
+			since, _ = strconv.ParseUint(q.Get("since_id"), 10, 64)
+			max, _   = strconv.ParseUint(q.Get("max_id"), 10, 64)
+			count, _ = strconv.Atoi(q.Get("count"))
+			out      = []map[string]interface{}{}
+		)
+		*queries = append(*queries, q)
+		for i := size; i >= 1 && len(out) < count; i-- {
+			id := timelineBase + uint64(i)
+			if id <= since || (max != 0 && id > max) {
+				continue
+			}
+			out = append(out, map[string]interface{}{
+				"id_str":     strconv.FormatUint(id, 10),
+				"created_at": now.Add(time.Duration(i) * time.Minute).Format(time.RubyDate),
+			})
+		}
+		json.NewEncoder(w).Encode(out)
+	}))
+}
+
+func collectTimeline(t *testing.T, it *TimelineIterator) (ids []uint64) {
+	for {
+		page, err := it.Next(context.Background())
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, tweet := range page {
+			ids = append(ids, tweet.Id()-timelineBase)
+		}
+	}
+}
+
+func TestTimelineIteratorBackward(t *testing.T) {
+	var (
+		queries []url.Values
+		server  = getTimelineServer(7, &queries)
+		c       = getHostTestClient(server)
+		params  = UserTimelineParams{ScreenName: "kurrik", Count: 3}.Values()
+		it      = NewTimelineIterator(c, PATH_USER_TIMELINE, params)
+		ids     = collectTimeline(t, it)
+	)
+	defer server.Close()
+	if fmt.Sprint(ids) != "[7 6 5 4 3 2 1]" {
+		t.Errorf("Unexpected Tweets %v", ids)
+	}
+	if max := queries[1].Get("max_id"); max != strconv.FormatUint(timelineBase+4, 10) {
+		t.Errorf("Expected max_id one less than the smallest ID, got %v", max)
+	}
+	if len(queries) != 4 {
+		t.Errorf("Expected iteration to stop on an empty page, got %v requests", len(queries))
+	}
+}
+
+func TestTimelineIteratorBounds(t *testing.T) {
+	var (
+		queries []url.Values
+		server  = getTimelineServer(10, &queries)
+		c       = getHostTestClient(server)
+		params  = UserTimelineParams{ScreenName: "kurrik", Count: 3}.Values()
+		it      *TimelineIterator
+		ids     []uint64
+	)
+	defer server.Close()
+	it = NewTimelineIterator(c, PATH_USER_TIMELINE, params)
+	it.Limit = 5
+	if ids = collectTimeline(t, it); fmt.Sprint(ids) != "[10 9 8 7 6]" {
+		t.Errorf("Expected Limit to stop iteration, got %v", ids)
+	}
+	it = NewTimelineIterator(c, PATH_USER_TIMELINE, params)
+	it.Since = time.Date(2020, 1, 1, 0, 8, 0, 0, time.UTC)
+	if ids = collectTimeline(t, it); fmt.Sprint(ids) != "[10 9 8]" {
+		t.Errorf("Expected Since to stop iteration, got %v", ids)
+	}
+}
+
+func TestTimelineIteratorForward(t *testing.T) {
+	var (
+		queries []url.Values
+		server  = getTimelineServer(8, &queries)
+		c       = getHostTestClient(server)
+		params  = TimelineParams{Count: 2, SinceId: timelineBase + 3}.Values()
+		it      *TimelineIterator
+		ids     []uint64
+	)
+	defer server.Close()
+	it = NewTimelineIterator(c, PATH_HOME_TIMELINE, params)
+	it.Direction = DIRECTION_FORWARD
+	if ids = collectTimeline(t, it); fmt.Sprint(ids) != "[8 7 6 5 4]" {
+		t.Errorf("Expected every Tweet after since_id, got %v", ids)
+	}
+	if since := queries[len(queries)-1].Get("since_id"); since != strconv.FormatUint(timelineBase+8, 10) {
+		t.Errorf("Expected since_id to advance to the newest Tweet, got %v", since)
+	}
+}
+
+func TestTimelineIteratorForwardMissingIds(t *testing.T) {
+	var (
+		requests int
+		server   = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests++; requests > 3 {
+				w.WriteHeader(500)
+				return
+			}
+			fmt.Fprint(w, `[{"text":"No ID"}]`)
+		}))
+		c      = getHostTestClient(server)
+		params = TimelineParams{SinceId: 5}.Values()
+		it     = NewTimelineIterator(c, PATH_HOME_TIMELINE, params)
+	)
+	defer server.Close()
+	it.Direction = DIRECTION_FORWARD
+	if ids := collectTimeline(t, it); len(ids) != 1 {
+		t.Errorf("Expected a single page, got %v", ids)
+	}
+	if requests != 1 {
+		t.Errorf("Expected iteration to stop without IDs, got %v requests", requests)
+	}
+}
+
+func TestSearchIterator(t *testing.T) {
+	var (
+		pages = map[string]string{
+			"":  `{"statuses":[{"id_str":"3"},{"id_str":"2"}],"search_metadata":{"next_results":"?max_id=1&q=go"}}`,
+			"1": `{"statuses":[{"id_str":"1"}],"search_metadata":{}}`,
+		}
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, pages[r.URL.Query().Get("max_id")])
+		}))
+		c       = getHostTestClient(server)
+		it      = NewSearchIterator(c, SearchParams{Query: "go"})
+		results SearchResults
+		ids     []string
+		err     error
+	)
+	defer server.Close()
+	for {
+		if results, err = it.Next(context.Background()); err != nil {
+			break
+		}
+		for _, tweet := range results.Statuses() {
+			ids = append(ids, tweet.IdStr())
+		}
+	}
+	if err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+	if fmt.Sprint(ids) != "[3 2 1]" {
+		t.Errorf("Unexpected Tweets %v", ids)
+	}
+}
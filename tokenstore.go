@@ -0,0 +1,472 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kurrik/oauth1a"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Returned by TokenStore.Get when no account matches the key.
+var ErrAccountNotFound = errors.New("Account not found in token store")
+
+// The credentials stored for one account.  At least one of UserId and
+// ScreenName identifies the account; either may be used to look it up.
+type StoredAccount struct {
+	UserId            uint64       `json:"user_id,omitempty"`
+	ScreenName        string       `json:"screen_name,omitempty"`
+	AccessToken       string       `json:"access_token,omitempty"`
+	AccessTokenSecret string       `json:"access_token_secret,omitempty"`
+	UserToken         *OAuth2Token `json:"oauth2_token,omitempty"`
+}
+
+// Returns the account's OAuth 1.0a credentials, or nil if it has none.
+func (a *StoredAccount) UserConfig() *oauth1a.UserConfig {
+	if a.AccessToken == "" {
+		return nil
+	}
+	return oauth1a.NewAuthorizedConfig(a.AccessToken, a.AccessTokenSecret)
+}
+
+// Returns true if key is the account's user ID or screen name.  Screen
+// names are compared without regard to case or a leading @.
+func (a *StoredAccount) Matches(key string) bool {
+	key = strings.TrimPrefix(key, "@")
+	if a.UserId != 0 && key == strconv.FormatUint(a.UserId, 10) {
+		return true
+	}
+	return a.ScreenName != "" && strings.EqualFold(key, a.ScreenName)
+}
+
+// Returns a key which finds the account in a TokenStore.
+func (a *StoredAccount) key() string {
+	if a.UserId != 0 {
+		return strconv.FormatUint(a.UserId, 10)
+	}
+	return a.ScreenName
+}
+
+// Returns true if a and b describe the same account.
+func (a *StoredAccount) sameAccount(b *StoredAccount) bool {
+	if a.UserId != 0 && b.UserId != 0 {
+		return a.UserId == b.UserId
+	}
+	return a.ScreenName != "" && strings.EqualFold(a.ScreenName, b.ScreenName)
+}
+
+// TokenStore persists account credentials.  Client consults it in
+// WithAccount, and the OAuth helpers save newly authorized users to it.
+type TokenStore interface {
+	// Returns the account whose user ID or screen name is key, or
+	// ErrAccountNotFound.
+	Get(key string) (*StoredAccount, error)
+	// Saves the account, replacing any stored account with the same user
+	// ID, or the same screen name if the user ID is not set.
+	Put(account *StoredAccount) error
+	// Removes the account whose user ID or screen name is key.  Removing an
+	// account which is not stored is not an error.
+	Delete(key string) error
+	// Returns every stored account.
+	List() ([]*StoredAccount, error)
+}
+
+// Replaces or adds account in accounts.
+func putAccount(accounts []*StoredAccount, account *StoredAccount) ([]*StoredAccount, error) {
+	if account.UserId == 0 && account.ScreenName == "" {
+		return accounts, fmt.Errorf("Stored account needs a user ID or screen name")
+	}
+	var copied = *account
+	for i, a := range accounts {
+		if a.sameAccount(account) {
+			accounts[i] = &copied
+			return accounts, nil
+		}
+	}
+	return append(accounts, &copied), nil
+}
+
+func getAccount(accounts []*StoredAccount, key string) (*StoredAccount, error) {
+	for _, a := range accounts {
+		if a.Matches(key) {
+			var copied = *a
+			return &copied, nil
+		}
+	}
+	return nil, ErrAccountNotFound
+}
+
+func deleteAccount(accounts []*StoredAccount, key string) []*StoredAccount {
+	var out = accounts[:0]
+	for _, a := range accounts {
+		if !a.Matches(key) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// A TokenStore which keeps accounts in memory.
+type MemoryTokenStore struct {
+	mu       sync.Mutex
+	accounts []*StoredAccount
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{}
+}
+
+func (s *MemoryTokenStore) Get(key string) (*StoredAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return getAccount(s.accounts, key)
+}
+
+func (s *MemoryTokenStore) Put(account *StoredAccount) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts, err = putAccount(s.accounts, account)
+	return
+}
+
+func (s *MemoryTokenStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts = deleteAccount(s.accounts, key)
+	return nil
+}
+
+func (s *MemoryTokenStore) List() ([]*StoredAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyAccounts(s.accounts), nil
+}
+
+func copyAccounts(accounts []*StoredAccount) []*StoredAccount {
+	var out = make([]*StoredAccount, len(accounts))
+	for i, a := range accounts {
+		var copied = *a
+		out[i] = &copied
+	}
+	return out
+}
+
+const (
+	TOKEN_STORE_VERSION    = 1
+	TOKEN_STORE_ITERATIONS = 200000
+	// Files with fewer PBKDF2 iterations than this are rejected.
+	TOKEN_STORE_MIN_ITERATIONS = 10000
+)
+
+// The on-disk format of a FileTokenStore.  Data is the AES-256-GCM
+// encryption of the JSON encoded accounts, under a key derived from the
+// passphrase with PBKDF2-HMAC-SHA256.
+type tokenStoreFile struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// A TokenStore which keeps accounts in a file encrypted with a key derived
+// from a passphrase.  The file is read on every call and replaced
+// atomically on every change, so other processes may read it, but writes
+// are only serialized within this process.  Changes made by several
+// processes at once may overwrite each other.
+type FileTokenStore struct {
+	Path string
+
+	mu         sync.Mutex
+	passphrase []byte
+	// The key derived for the salt and iteration count of the file,
+	// cached because deriving it is deliberately slow.
+	salt       []byte
+	iterations int
+	key        []byte
+}
+
+// Creates a store in the file at path, which is created on the first Put
+// if it does not exist.
+func NewFileTokenStore(path string, passphrase string) *FileTokenStore {
+	return &FileTokenStore{
+		Path:       path,
+		passphrase: []byte(passphrase),
+	}
+}
+
+func (s *FileTokenStore) Get(key string) (*StoredAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accounts, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return getAccount(accounts, key)
+}
+
+func (s *FileTokenStore) Put(account *StoredAccount) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accounts, err := s.load()
+	if err != nil {
+		return err
+	}
+	if accounts, err = putAccount(accounts, account); err != nil {
+		return err
+	}
+	return s.save(accounts)
+}
+
+func (s *FileTokenStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accounts, err := s.load()
+	if err != nil {
+		return err
+	}
+	return s.save(deleteAccount(accounts, key))
+}
+
+func (s *FileTokenStore) List() ([]*StoredAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Returns the key for salt and iterations, deriving it if either has
+// changed.
+func (s *FileTokenStore) deriveKey(salt []byte, iterations int) []byte {
+	if s.key == nil || iterations != s.iterations || !hmac.Equal(salt, s.salt) {
+		s.salt = salt
+		s.iterations = iterations
+		s.key = pbkdf2(sha256.New, s.passphrase, salt, iterations, 32)
+	}
+	return s.key
+}
+
+func (s *FileTokenStore) load() (accounts []*StoredAccount, err error) {
+	var (
+		b     []byte
+		file  tokenStoreFile
+		gcm   cipher.AEAD
+		plain []byte
+	)
+	if b, err = ioutil.ReadFile(s.Path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return
+	}
+	if err = json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("Could not parse token store %v: %v", s.Path, err)
+	}
+	if file.Version != TOKEN_STORE_VERSION {
+		return nil, fmt.Errorf("Unsupported token store version %v", file.Version)
+	}
+	if file.Iterations < TOKEN_STORE_MIN_ITERATIONS {
+		return nil, fmt.Errorf("Token store %v has too few key derivation iterations: %v", s.Path, file.Iterations)
+	}
+	if gcm, err = newGCM(s.deriveKey(file.Salt, file.Iterations)); err != nil {
+		return
+	}
+	if plain, err = gcm.Open(nil, file.Nonce, file.Data, nil); err != nil {
+		return nil, fmt.Errorf("Could not decrypt token store %v: wrong passphrase or corrupt file", s.Path)
+	}
+	err = json.Unmarshal(plain, &accounts)
+	return
+}
+
+func (s *FileTokenStore) save(accounts []*StoredAccount) (err error) {
+	var (
+		file = tokenStoreFile{
+			Version:    TOKEN_STORE_VERSION,
+			Iterations: TOKEN_STORE_ITERATIONS,
+			Salt:       s.salt,
+		}
+		gcm   cipher.AEAD
+		plain []byte
+		b     []byte
+		tmp   *os.File
+	)
+	if file.Salt == nil {
+		file.Salt = make([]byte, 16)
+		if _, err = rand.Read(file.Salt); err != nil {
+			return
+		}
+	}
+	if gcm, err = newGCM(s.deriveKey(file.Salt, file.Iterations)); err != nil {
+		return
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(file.Nonce); err != nil {
+		return
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].UserId < accounts[j].UserId
+	})
+	if plain, err = json.Marshal(accounts); err != nil {
+		return
+	}
+	file.Data = gcm.Seal(nil, file.Nonce, plain, nil)
+	if b, err = json.Marshal(file); err != nil {
+		return
+	}
+	if tmp, err = ioutil.TempFile(filepath.Dir(s.Path), ".tokens"); err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		return
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Derives a key from password and salt as described in RFC 8018, section
+// 5.2.
+func pbkdf2(h func() hash.Hash, password []byte, salt []byte, iterations int, keyLen int) []byte {
+	var (
+		prf    = hmac.New(h, password)
+		size   = prf.Size()
+		blocks = (keyLen + size - 1) / size
+		out    = make([]byte, 0, blocks*size)
+		buf    = make([]byte, 4)
+		u      []byte
+		t      = make([]byte, size)
+	)
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf, uint32(block))
+		prf.Write(buf)
+		u = prf.Sum(u[:0])
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t...)
+	}
+	return out[:keyLen]
+}
+
+// Returns a view of the client which signs requests as the account in
+// TokenStore whose user ID or screen name is key.  See WithUser.  If the
+// account's OAuth 2.0 token is refreshed, the new token is saved back to
+// the store.
+func (c *Client) WithAccount(key string) (*Client, error) {
+	account, err := c.storedAccount(key)
+	if err != nil {
+		return nil, err
+	}
+	var view = c.WithUser(account.UserConfig())
+	view.UserToken = account.UserToken
+	view.account = account
+	return view, nil
+}
+
+// Changes the credentials of this client to those of the account in
+// TokenStore whose user ID or screen name is key.  Like SetUser, this is
+// not safe to call while other goroutines are using the client.
+func (c *Client) SetAccount(key string) error {
+	account, err := c.storedAccount(key)
+	if err != nil {
+		return err
+	}
+	c.SetUser(account.UserConfig())
+	c.SetUserToken(account.UserToken)
+	c.account = account
+	return nil
+}
+
+func (c *Client) storedAccount(key string) (*StoredAccount, error) {
+	if c.TokenStore == nil {
+		return nil, fmt.Errorf("Client has no TokenStore")
+	}
+	return c.TokenStore.Get(key)
+}
+
+// Saves a newly authorized user to TokenStore, if one is set.
+func (c *Client) storeAuthorizedUser(au *AuthorizedUser) error {
+	if c.TokenStore == nil {
+		return nil
+	}
+	var account = &StoredAccount{
+		UserId:            au.UserId,
+		ScreenName:        au.ScreenName,
+		AccessToken:       au.AccessTokenKey,
+		AccessTokenSecret: au.AccessTokenSecret,
+	}
+	if existing, err := c.TokenStore.Get(account.key()); err == nil {
+		account.UserToken = existing.UserToken
+	} else if err != ErrAccountNotFound {
+		return err
+	}
+	return c.TokenStore.Put(account)
+}
+
+// Records a new OAuth 2.0 token on the client's stored account and returns
+// the updated account to be saved with storeAccount, or nil if the client
+// has no TokenStore or account.  The caller must hold oauth2Mu.
+func (c *Client) accountWithToken(token *OAuth2Token) *StoredAccount {
+	if c.TokenStore == nil || c.account == nil {
+		return nil
+	}
+	var account = *c.account
+	account.UserToken = token
+	c.account = &account
+	return &account
+}
+
+// Saves an account returned by accountWithToken to the TokenStore.  It is
+// called without holding oauth2Mu, so that a slow store does not block
+// requests.
+func (c *Client) storeAccount(account *StoredAccount) error {
+	if account == nil {
+		return nil
+	}
+	return c.TokenStore.Put(account)
+}
@@ -0,0 +1,230 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	// Test vectors from RFC 6070 (HMAC-SHA1) and RFC 7914, section 11
+	// (HMAC-SHA256).
+	var cases = []struct {
+		hash       func() hash.Hash
+		password   string
+		salt       string
+		iterations int
+		keyLen     int
+		expected   string
+	}{
+		{sha1.New, "password", "salt", 1, 20, "0c60c80f961f0e71f3a9b524af6012062fe037a6"},
+		{sha1.New, "password", "salt", 2, 20, "ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957"},
+		{sha1.New, "password", "salt", 4096, 20, "4b007901b765489abead49d926f721d065a429c1"},
+		{sha1.New, "passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, 25, "3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038"},
+		{sha1.New, "pass\x00word", "sa\x00lt", 4096, 16, "56fa6aa75548099dcc37d7f03425e0c3"},
+		{sha256.New, "passwd", "salt", 1, 64, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{sha256.New, "password", "salt", 4096, 32, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+	for _, tc := range cases {
+		got := hex.EncodeToString(pbkdf2(tc.hash, []byte(tc.password), []byte(tc.salt), tc.iterations, tc.keyLen))
+		if got != tc.expected {
+			t.Errorf("pbkdf2(%q, %q, %v) = %v, expected %v", tc.password, tc.salt, tc.iterations, got, tc.expected)
+		}
+	}
+}
+
+func testTokenStore(t *testing.T, store TokenStore) {
+	if _, err := store.Get("6253282"); err != ErrAccountNotFound {
+		t.Fatalf("Expected ErrAccountNotFound, got %v", err)
+	}
+	if err := store.Put(&StoredAccount{AccessToken: "x"}); err == nil {
+		t.Errorf("Expected error storing account without id or screen name")
+	}
+	var accounts = []*StoredAccount{
+		{UserId: 6253282, ScreenName: "TwitterAPI", AccessToken: "token1", AccessTokenSecret: "secret1"},
+		{UserId: 783214, ScreenName: "Twitter", UserToken: &OAuth2Token{AccessToken: "oauth2", TokenType: "bearer"}},
+	}
+	for _, a := range accounts {
+		if err := store.Put(a); err != nil {
+			t.Fatalf("Put returned error: %v", err)
+		}
+	}
+	for _, key := range []string{"6253282", "TwitterAPI", "@twitterapi"} {
+		a, err := store.Get(key)
+		if err != nil {
+			t.Fatalf("Get(%v) returned error: %v", key, err)
+		}
+		if a.AccessToken != "token1" || a.UserConfig().AccessTokenSecret != "secret1" {
+			t.Errorf("Get(%v) returned unexpected account %v", key, a)
+		}
+	}
+	if a, _ := store.Get("twitter"); a.UserToken == nil || a.UserToken.AccessToken != "oauth2" || a.UserConfig() != nil {
+		t.Errorf("Unexpected OAuth2 account %v", a)
+	}
+	// Renamed accounts replace the old entry.
+	if err := store.Put(&StoredAccount{UserId: 6253282, ScreenName: "XDevelopers", AccessToken: "token2"}); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if a, err := store.Get("xdevelopers"); err != nil || a.AccessToken != "token2" {
+		t.Errorf("Expected updated account, got %v %v", a, err)
+	}
+	if _, err := store.Get("TwitterAPI"); err != ErrAccountNotFound {
+		t.Errorf("Expected old screen name to be gone, got %v", err)
+	}
+	if err := store.Delete("783214"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if list, err := store.List(); err != nil || len(list) != 1 || list[0].UserId != 6253282 {
+		t.Errorf("Unexpected accounts after delete %v %v", list, err)
+	}
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	testTokenStore(t, NewMemoryTokenStore())
+}
+
+func TestFileTokenStore(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "tokens")
+	testTokenStore(t, NewFileTokenStore(path, "passphrase"))
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("token2")) || bytes.Contains(b, []byte("XDevelopers")) {
+		t.Errorf("Token store is not encrypted: %s", b)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Token store has mode %v", info.Mode())
+	}
+	if a, err := NewFileTokenStore(path, "passphrase").Get("6253282"); err != nil || a.AccessToken != "token2" {
+		t.Errorf("Could not reopen token store: %v %v", a, err)
+	}
+	if _, err := NewFileTokenStore(path, "wrong").Get("6253282"); err == nil {
+		t.Errorf("Expected error with wrong passphrase")
+	}
+}
+
+func TestFileTokenStoreIterations(t *testing.T) {
+	var (
+		path  = filepath.Join(t.TempDir(), "tokens")
+		store = NewFileTokenStore(path, "passphrase")
+		file  tokenStoreFile
+		gcm   cipher.AEAD
+		plain []byte
+		b     []byte
+		err   error
+	)
+	if err = store.Put(&StoredAccount{UserId: 1, ScreenName: "kurrik", AccessToken: "token"}); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	// Rewrite the file with the same salt but another iteration count, as
+	// a different version of the library might.
+	b, _ = ioutil.ReadFile(path)
+	json.Unmarshal(b, &file)
+	gcm, _ = newGCM(pbkdf2(sha256.New, []byte("passphrase"), file.Salt, file.Iterations, 32))
+	if plain, err = gcm.Open(nil, file.Nonce, file.Data, nil); err != nil {
+		t.Fatalf("Could not decrypt token store: %v", err)
+	}
+	var rewrite = func(iterations int) {
+		file.Iterations = iterations
+		if iterations > 0 {
+			gcm, _ = newGCM(pbkdf2(sha256.New, []byte("passphrase"), file.Salt, iterations, 32))
+			file.Data = gcm.Seal(nil, file.Nonce, plain, nil)
+		}
+		b, _ = json.Marshal(file)
+		ioutil.WriteFile(path, b, 0600)
+	}
+	rewrite(TOKEN_STORE_MIN_ITERATIONS)
+	if a, err := store.Get("kurrik"); err != nil || a.AccessToken != "token" {
+		t.Errorf("Could not read file with different iterations: %v %v", a, err)
+	}
+	for _, iterations := range []int{0, -1, TOKEN_STORE_MIN_ITERATIONS - 1} {
+		rewrite(iterations)
+		if _, err := store.Get("kurrik"); err == nil || !strings.Contains(err.Error(), "iterations") {
+			t.Errorf("Expected %v iterations to be rejected, got %v", iterations, err)
+		}
+	}
+}
+
+func TestCompleteUserAuthStoresAccount(t *testing.T) {
+	server, c := getAuthTestServer(t, nil)
+	defer server.Close()
+	c.TokenStore = NewMemoryTokenStore()
+
+	if _, err := c.BeginUserAuth("http://localhost/callback"); err != nil {
+		t.Fatalf("BeginUserAuth returned error: %v", err)
+	}
+	if _, err := c.CompleteUserAuth("request", "verifier"); err != nil {
+		t.Fatalf("CompleteUserAuth returned error: %v", err)
+	}
+	view, err := c.WithAccount("@twitterapi")
+	if err != nil {
+		t.Fatalf("WithAccount returned error: %v", err)
+	}
+	if view.User == nil || view.User.AccessTokenKey != "access" || c.User != nil {
+		t.Errorf("Unexpected users %v %v", view.User, c.User)
+	}
+	if _, err = c.WithAccount("nobody"); err != ErrAccountNotFound {
+		t.Errorf("Expected ErrAccountNotFound, got %v", err)
+	}
+	if err = c.SetAccount("6253282"); err != nil || c.User.AccessTokenSecret != "access_secret" {
+		t.Errorf("SetAccount failed: %v %v", c.User, err)
+	}
+}
+
+func TestWithAccountSavesRefreshedToken(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("refresh_token") != "refresh1" {
+			t.Errorf("Unexpected refresh token %v", r.Form.Get("refresh_token"))
+		}
+		fmt.Fprint(w, `{"token_type":"bearer","access_token":"access2","refresh_token":"refresh2","expires_in":7200}`)
+	}))
+	defer server.Close()
+
+	var c = getHostTestClient(server)
+	c.OAuth2 = &OAuth2Config{ClientId: "client"}
+	c.TokenStore = NewMemoryTokenStore()
+	c.TokenStore.Put(&StoredAccount{
+		UserId:    783214,
+		UserToken: &OAuth2Token{AccessToken: "access1", RefreshToken: "refresh1", TokenType: "bearer"},
+	})
+	view, err := c.WithAccount("783214")
+	if err != nil {
+		t.Fatalf("WithAccount returned error: %v", err)
+	}
+	if err = view.RefreshUserToken(context.Background()); err != nil {
+		t.Fatalf("RefreshUserToken returned error: %v", err)
+	}
+	if a, _ := c.TokenStore.Get("783214"); a.UserToken.RefreshToken != "refresh2" {
+		t.Errorf("Refreshed token not saved: %v", a.UserToken)
+	}
+}
@@ -17,28 +17,97 @@ package twittergo
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/kurrik/oauth1a"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Implements a Twitter client.
+//
+// A Client may be used by several goroutines at once, as long as its
+// fields are not changed while requests are in flight.  To make requests
+// for several users concurrently, create a view for each with WithUser
+// rather than calling SetUser.
 type Client struct {
 	Host       string
+	UploadHost string
+	StreamHost string
 	OAuth      *oauth1a.Service
 	User       *oauth1a.UserConfig
 	AppToken   *BearerToken
 	HttpClient *http.Client
+	Retry      *RetryPolicy
+	RateLimits *RateLimitTracker
+	// If set, responses parsed by the client's helpers decode numbers
+	// into json.Number.  See UseNumber.
+	UseNumber bool
+	// OAuth 2.0 user context configuration and token.  See BeginOAuth2.
+	OAuth2    *OAuth2Config
+	UserToken *OAuth2Token
+	// Called with the new token whenever UserToken is refreshed, so that
+	// it may be saved.
+	OnUserTokenRefresh func(token *OAuth2Token)
+	// If set, authenticates every request instead of the default choice
+	// between User, UserToken and the app-only bearer token.
+	Auth Authenticator
+	// If set, WithAccount and SetAccount read credentials from it, and
+	// users authorized through this client are saved to it.
+	TokenStore TokenStore
+	// If set, each request is logged with its status, latency and rate
+	// limit, with credentials redacted.
+	Logger Logger
+	// If positive, up to this many bytes of each request and response body
+	// are also logged.
+	LogBodyLimit int
+
+	// Wraps HttpClient when sending signed requests.  See Use.
+	middleware []Middleware
+	// The stored account this client signs as, if it was selected with
+	// WithAccount or SetAccount.
+	account *StoredAccount
+	// The refresh of this client's UserToken in progress, if any.
+	userFetch *tokenFetch
+	// The client this one is a view of, which holds the shared state
+	// below.  Nil for clients returned by NewClient.
+	parent *Client
+	// Guards AppToken and appFetch.
+	appMu    sync.Mutex
+	appFetch *tokenFetch
+	// Guards UserToken, userFetch and account on every view.
+	oauth2Mu    sync.Mutex
+	pendingAuth pendingAuth
+	// Guards clockOffset.  See ClockOffset.
+	clockMu     sync.Mutex
+	clockOffset time.Duration
+}
+
+// A token request which other goroutines may wait for.
+type tokenFetch struct {
+	done chan struct{}
+	err  error
 }
 
+const (
+	DEFAULT_API_HOST    = "api.twitter.com"
+	DEFAULT_UPLOAD_HOST = "upload.twitter.com"
+	DEFAULT_STREAM_HOST = "stream.twitter.com"
+)
+
+const (
+	PATH_OAUTH2_APP_TOKEN        = "/oauth2/token"
+	PATH_OAUTH2_INVALIDATE_TOKEN = "/oauth2/invalidate_token"
+)
+
 type BearerToken struct {
 	AccessToken string
 }
@@ -57,11 +126,11 @@ func getEnvEitherCase(k string) string {
 //
 // When using a proxy, disable TLS certificate verification with the following:
 //     export TLS_INSECURE=1
+//
+// See NewClientWithOptions to configure the transport explicitly instead.
 func NewClient(config *oauth1a.ClientConfig, user *oauth1a.UserConfig) *Client {
 	var (
-		host      = "api.twitter.com"
-		base      = "https://" + host
-		req, _    = http.NewRequest("GET", "https://api.twitter.com", nil)
+		req, _    = http.NewRequest("GET", "https://"+DEFAULT_API_HOST, nil)
 		proxy, _  = http.ProxyFromEnvironment(req)
 		transport *http.Transport
 		tlsconfig *tls.Config
@@ -80,13 +149,20 @@ func NewClient(config *oauth1a.ClientConfig, user *oauth1a.UserConfig) *Client {
 	} else {
 		transport = &http.Transport{}
 	}
+	return newClient(config, user, &http.Client{Transport: transport}, DEFAULT_API_HOST)
+}
+
+// Creates a client for the API at host, sending requests with httpClient.
+func newClient(config *oauth1a.ClientConfig, user *oauth1a.UserConfig, httpClient *http.Client, host string) *Client {
+	var base = "https://" + host
 	return &Client{
-		Host: host,
-		HttpClient: &http.Client{
-			Transport: transport,
-		},
-		User:     user,
-		AppToken: nil,
+		Host:       host,
+		UploadHost: DEFAULT_UPLOAD_HOST,
+		StreamHost: DEFAULT_STREAM_HOST,
+		HttpClient: httpClient,
+		User:       user,
+		AppToken:   nil,
+		RateLimits: NewRateLimitTracker(),
 		OAuth: &oauth1a.Service{
 			RequestURL:   base + "/oauth/request_token",
 			AuthorizeURL: base + "/oauth/authorize",
@@ -97,14 +173,88 @@ func NewClient(config *oauth1a.ClientConfig, user *oauth1a.UserConfig) *Client {
 	}
 }
 
+// Returns the options the client's helpers use when parsing responses.
+func (c *Client) parseOptions() []ParseOption {
+	if c.UseNumber {
+		return []ParseOption{UseNumber()}
+	}
+	return nil
+}
+
 // Changes the user authorization credentials for this client.
+// This is not safe to call while other goroutines are using the client;
+// see WithUser.
 func (c *Client) SetUser(user *oauth1a.UserConfig) {
 	c.User = user
+	c.account = nil
+}
+
+// Returns a view of the client which signs requests as user.  Views are
+// cheap to create and share the client's transport, configuration, app-only
+// bearer token and rate limit tracker, so limits reported for one view's
+// requests are visible to all of them.
+func (c *Client) WithUser(user *oauth1a.UserConfig) *Client {
+	return &Client{
+		Host:               c.Host,
+		UploadHost:         c.UploadHost,
+		StreamHost:         c.StreamHost,
+		OAuth:              c.OAuth,
+		User:               user,
+		HttpClient:         c.HttpClient,
+		Retry:              c.Retry,
+		RateLimits:         c.RateLimits,
+		UseNumber:          c.UseNumber,
+		OAuth2:             c.OAuth2,
+		OnUserTokenRefresh: c.OnUserTokenRefresh,
+		Auth:               c.Auth,
+		TokenStore:         c.TokenStore,
+		Logger:             c.Logger,
+		LogBodyLimit:       c.LogBodyLimit,
+		middleware:         c.middleware[:len(c.middleware):len(c.middleware)],
+		parent:             c.root(),
+	}
+}
+
+// Returns the client holding state shared by all views of this client.
+func (c *Client) root() *Client {
+	if c.parent != nil {
+		return c.parent
+	}
+	return c
+}
+
+// Returns the key under which rate limits for this client's current
+// credentials are tracked.  Requests signed with different user tokens, or
+// with the app-only bearer token, are subject to separate limits.
+func (c *Client) Identity() string {
+	return c.authenticator(context.Background()).Identity(c)
+}
+
+// Returns how many requests remain for the endpoint at path using this
+// client's current credentials, as last reported by the API.
+// The boolean is false if no limit has been recorded.
+func (c *Client) RateLimitRemaining(path string) (uint32, bool) {
+	if c.RateLimits == nil {
+		return 0, false
+	}
+	return c.RateLimits.Remaining(c.Identity(), path)
+}
+
+// Returns when the limit for the endpoint at path resets for this client's
+// current credentials, or the zero time if no limit has been recorded.
+func (c *Client) RateLimitResetAt(path string) time.Time {
+	if c.RateLimits == nil {
+		return time.Time{}
+	}
+	return c.RateLimits.ResetAt(c.Identity(), path)
 }
 
 // Sets the app-only auth token to the specified string.
 func (c *Client) SetAppToken(token string) {
-	c.AppToken = &BearerToken{
+	var r = c.root()
+	r.appMu.Lock()
+	defer r.appMu.Unlock()
+	r.AppToken = &BearerToken{
 		AccessToken: token,
 	}
 }
@@ -114,29 +264,130 @@ func (c *Client) SetAppToken(token string) {
 // You may call SetAppToken with the value returned by this call in order
 // to restore a previously-fetched bearer token to use.
 func (c *Client) GetAppToken() string {
-	if c.AppToken == nil {
+	var r = c.root()
+	r.appMu.Lock()
+	defer r.appMu.Unlock()
+	if r.AppToken == nil {
 		return ""
 	}
-	return c.AppToken.AccessToken
+	return r.AppToken.AccessToken
+}
+
+// Clears the app-only auth token if it is still stale, so that a token
+// fetched by another goroutine in the meantime is kept.
+func (c *Client) clearAppToken(stale string) {
+	var r = c.root()
+	r.appMu.Lock()
+	defer r.appMu.Unlock()
+	if r.AppToken != nil && r.AppToken.AccessToken == stale {
+		r.AppToken = nil
+	}
+}
+
+// Returns the app-only auth token, fetching one if needed.  Concurrent
+// callers wait for a single request rather than each fetching a token.
+func (c *Client) appToken(ctx context.Context) (token string, err error) {
+	var r = c.root()
+	for {
+		r.appMu.Lock()
+		if r.AppToken != nil {
+			token = r.AppToken.AccessToken
+			r.appMu.Unlock()
+			return
+		}
+		if f := r.appFetch; f != nil {
+			r.appMu.Unlock()
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+			// Try again if the fetch only failed because the context of
+			// the goroutine which made it was done.
+			if f.err != nil && !errors.Is(f.err, context.Canceled) && !errors.Is(f.err, context.DeadlineExceeded) {
+				return "", f.err
+			}
+			continue
+		}
+		f := &tokenFetch{done: make(chan struct{})}
+		r.appFetch = f
+		r.appMu.Unlock()
+		f.err = c.FetchAppTokenContext(ctx)
+		r.appMu.Lock()
+		r.appFetch = nil
+		r.appMu.Unlock()
+		close(f.done)
+		if f.err != nil {
+			return "", f.err
+		}
+	}
 }
 
 // Requests a new app auth bearer token and stores it.
 func (c *Client) FetchAppToken() (err error) {
+	return c.FetchAppTokenContext(context.Background())
+}
+
+// Requests a new app auth bearer token and stores it, aborting the request
+// if ctx is done first.  If the request fails, the error is usually of type
+// Errors or ResponseError, as returned by Parse.
+func (c *Client) FetchAppTokenContext(ctx context.Context) (err error) {
+	var rj struct {
+		TokenType   string `json:"token_type"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = c.appTokenRequest(ctx, PATH_OAUTH2_APP_TOKEN, "grant_type=client_credentials", &rj); err != nil {
+		return
+	}
+	if rj.TokenType != "bearer" {
+		return fmt.Errorf("Got invalid token type: %v", rj.TokenType)
+	}
+	if rj.AccessToken == "" {
+		return fmt.Errorf("Got empty access token")
+	}
+	c.SetAppToken(rj.AccessToken)
+	return nil
+}
+
+// Revokes the current app auth bearer token and clears it from the client.
+// Later requests without user credentials will fetch a new token.
+func (c *Client) InvalidateAppToken() (err error) {
+	return c.InvalidateAppTokenContext(context.Background())
+}
+
+// InvalidateAppTokenContext is like InvalidateAppToken, but aborts the
+// request if ctx is done first.
+func (c *Client) InvalidateAppTokenContext(ctx context.Context) (err error) {
+	var (
+		token = c.GetAppToken()
+		body  = "access_token=" + url.QueryEscape(token)
+		rj    = map[string]interface{}{}
+	)
+	if token == "" {
+		return fmt.Errorf("No app token to invalidate")
+	}
+	if err = c.appTokenRequest(ctx, PATH_OAUTH2_INVALIDATE_TOKEN, body, &rj); err != nil {
+		return
+	}
+	c.clearAppToken(token)
+	return
+}
+
+// Posts body to one of the app auth token endpoints, authenticated with the
+// consumer key and secret, and parses the response into out.
+func (c *Client) appTokenRequest(ctx context.Context, path string, body string, out interface{}) (err error) {
 	var (
 		req  *http.Request
 		resp *http.Response
-		rb   []byte
-		rj   = map[string]interface{}{}
-		url  = fmt.Sprintf("https://%v/oauth2/token", c.Host)
+		url  = fmt.Sprintf("https://%v%v", c.Host, path)
 		ct   = "application/x-www-form-urlencoded;charset=UTF-8"
-		body = "grant_type=client_credentials"
 		ek   = oauth1a.Rfc3986Escape(c.OAuth.ClientConfig.ConsumerKey)
 		es   = oauth1a.Rfc3986Escape(c.OAuth.ClientConfig.ConsumerSecret)
 		cred = fmt.Sprintf("%v:%v", ek, es)
 		ec   = base64.StdEncoding.EncodeToString([]byte(cred))
 		h    = fmt.Sprintf("Basic %v", ec)
 	)
-	req, err = http.NewRequest("POST", url, bytes.NewBufferString(body))
+	req, err = http.NewRequestWithContext(ctx, "POST", url, bytes.NewBufferString(body))
 	if err != nil {
 		return
 	}
@@ -145,43 +396,41 @@ func (c *Client) FetchAppToken() (err error) {
 	if resp, err = c.HttpClient.Do(req); err != nil {
 		return
 	}
-	if resp.StatusCode != 200 {
-		err = fmt.Errorf("Got HTTP %v instead of 200", resp.StatusCode)
-		return
-	}
-	if rb, err = ioutil.ReadAll(resp.Body); err != nil {
-		return
-	}
-	if err = json.Unmarshal(rb, &rj); err != nil {
-		return
-	}
-	var (
-		token_type   = rj["token_type"].(string)
-		access_token = rj["access_token"].(string)
-	)
-	if token_type != "bearer" {
-		err = fmt.Errorf("Got invalid token type: %v", token_type)
-	}
-	c.SetAppToken(access_token)
-	return nil
+	return (*APIResponse)(resp).ParseContext(ctx, out)
 }
 
 // Signs the request with app-only auth, fetching a bearer token if needed.
 func (c *Client) Sign(req *http.Request) (err error) {
-	if c.AppToken == nil {
-		if err = c.FetchAppToken(); err != nil {
-			return
-		}
+	return c.SignContext(req.Context(), req)
+}
+
+// Signs the request with app-only auth, fetching a bearer token with the
+// supplied context if needed.
+func (c *Client) SignContext(ctx context.Context, req *http.Request) (err error) {
+	var token string
+	if token, err = c.appToken(ctx); err != nil {
+		return
 	}
 	var (
-		h = fmt.Sprintf("Bearer %v", c.AppToken.AccessToken)
+		h = fmt.Sprintf("Bearer %v", token)
 	)
 	req.Header.Set("Authorization", h)
 	return
 }
 
-// Sends a HTTP request through this instance's HTTP client.
+// Sends a HTTP request through this instance's HTTP client, authenticated
+// as described by Authenticator.
+// If the client has a RetryPolicy, rate limited requests are re-signed and
+// sent again once the limit resets.  If the client has a RateLimitTracker,
+// it is updated with the limits reported in each response.
 func (c *Client) SendRequest(req *http.Request) (resp *APIResponse, err error) {
+	return c.SendRequestContext(req.Context(), req)
+}
+
+// SendRequestContext is like SendRequest, but the request is sent with the
+// supplied context.  Cancelling ctx aborts the request, including any time
+// spent waiting for a rate limit to reset.
+func (c *Client) SendRequestContext(ctx context.Context, req *http.Request) (resp *APIResponse, err error) {
 	u := req.URL.String()
 	if !strings.HasPrefix(u, "http") {
 		u = fmt.Sprintf("https://%v%v", c.Host, u)
@@ -190,15 +439,91 @@ func (c *Client) SendRequest(req *http.Request) (resp *APIResponse, err error) {
 			return
 		}
 	}
-	if c.User != nil {
-		c.OAuth.Sign(req, c.User)
-	} else {
-		if err = c.Sign(req); err != nil {
+	if ctx != req.Context() {
+		req = req.WithContext(ctx)
+	}
+	var (
+		auth     = c.authenticator(ctx)
+		identity = auth.Identity(c)
+	)
+	if c.RateLimits != nil {
+		if err = c.RateLimits.preflight(ctx, identity, req.URL.Path); err != nil {
 			return
 		}
 	}
+	var policy = c.retryPolicy(ctx)
+	if policy != nil || isAppAuth(auth) {
+		if err = bufferBody(req); err != nil {
+			return
+		}
+	}
+	for attempt := 1; ; attempt++ {
+		if resp, err = c.send(ctx, auth, req); err != nil {
+			return
+		}
+		if c.RateLimits != nil {
+			c.RateLimits.record(identity, req.URL.Path, resp)
+		}
+		wait, retry := policy.next(attempt, resp)
+		if !retry {
+			return
+		}
+		discardBody(resp)
+		if err = sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+		if err = rewindBody(req); err != nil {
+			return
+		}
+	}
+}
+
+// Reports whether auth signs with the app-only bearer token.
+func isAppAuth(auth Authenticator) bool {
+	switch auth.(type) {
+	case AppAuth, *AppAuth:
+		return true
+	}
+	return false
+}
+
+// Signs and sends a single HTTP request.  If the app-only bearer token has
+// been invalidated or expired, a new one is fetched and the request is sent
+// once more.  Likewise, if an OAuth 1.0a request is rejected because the
+// local clock is wrong, the clock offset is corrected from the response and
+// the request is sent once more, provided its body can be rewound.
+func (c *Client) send(ctx context.Context, auth Authenticator, req *http.Request) (resp *APIResponse, err error) {
+	if resp, err = c.sendSigned(ctx, auth, req); err != nil {
+		return
+	}
+	var retry bool
+	switch auth.(type) {
+	case AppAuth, *AppAuth:
+		if resp.hasErrorCode(ctx, ERROR_INVALID_TOKEN) {
+			c.clearAppToken(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+			retry = true
+		}
+	case UserAuth, *UserAuth:
+		if resp.hasErrorCode(ctx, ERROR_TIMESTAMP_OUT_OF_BOUNDS) {
+			retry = c.correctClock(resp) && (req.Body == nil || req.GetBody != nil)
+		}
+	}
+	if !retry {
+		return
+	}
+	discardBody(resp)
+	if err = rewindBody(req); err != nil {
+		return nil, err
+	}
+	return c.sendSigned(ctx, auth, req)
+}
+
+func (c *Client) sendSigned(ctx context.Context, auth Authenticator, req *http.Request) (resp *APIResponse, err error) {
+	if err = auth.Authenticate(ctx, c, req); err != nil {
+		return
+	}
 	var r *http.Response
-	r, err = c.HttpClient.Do(req)
+	r, err = c.doer().Do(req)
 	resp = (*APIResponse)(r)
 	return
 }
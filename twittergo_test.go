@@ -0,0 +1,367 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittergo
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurrik/oauth1a"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Returns a client with user credentials which sends its requests to server.
+func getTestClient(server *httptest.Server) *Client {
+	var (
+		config = &oauth1a.ClientConfig{
+			ConsumerKey:    "consumer_key",
+			ConsumerSecret: "consumer_secret",
+		}
+		user = oauth1a.NewAuthorizedConfig("token", "secret")
+		c    = NewClient(config, user)
+	)
+	c.HttpClient = server.Client()
+	return c
+}
+
+// Returns a client which sends requests for relative URLs to server, which
+// must have been started with httptest.NewTLSServer.
+func getHostTestClient(server *httptest.Server) *Client {
+	c := getTestClient(server)
+	c.Host = server.Listener.Addr().String()
+	return c
+}
+
+func TestSendRequestSignsWithUser(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"id_str":"1234"}`)
+	}))
+	defer server.Close()
+
+	var (
+		c     = getTestClient(server)
+		req   *http.Request
+		resp  *APIResponse
+		tweet = &Tweet{}
+		err   error
+	)
+	req, _ = http.NewRequest("GET", server.URL+"/1.1/statuses/show.json?id=1234", nil)
+	if resp, err = c.SendRequest(req); err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	if err = resp.Parse(tweet); err != nil {
+		t.Fatalf("Unexpected error parsing response: %v", err)
+	}
+	if tweet.IdStr() != "1234" {
+		t.Errorf("Expected Tweet 1234, got %v", tweet.IdStr())
+	}
+	if !strings.HasPrefix(auth, "OAuth ") || !strings.Contains(auth, `oauth_token="token"`) {
+		t.Errorf("Request was not signed with user credentials: %v", auth)
+	}
+}
+
+func TestSendRequestSignsWithAppToken(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	var (
+		c   = getTestClient(server)
+		req *http.Request
+		err error
+	)
+	c.SetUser(nil)
+	c.SetAppToken("apptoken")
+	req, _ = http.NewRequest("GET", server.URL+"/1.1/search/tweets.json?q=go", nil)
+	if _, err = c.SendRequest(req); err != nil {
+		t.Fatalf("Unexpected error sending request: %v", err)
+	}
+	if auth != "Bearer apptoken" {
+		t.Errorf("Expected bearer authorization, got %v", auth)
+	}
+}
+
+func TestSendRequestContextCancelled(t *testing.T) {
+	var (
+		release = make(chan bool)
+		server  = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		c           = getTestClient(server)
+		req         *http.Request
+		err         error
+		ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	)
+	defer server.Close()
+	defer close(release)
+	defer cancel()
+	req, _ = http.NewRequest("GET", server.URL+"/1.1/statuses/show.json?id=1234", nil)
+	if _, err = c.SendRequestContext(ctx, req); err == nil {
+		t.Fatalf("Expected an error when the context deadline passes")
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("Expected the context deadline to have passed, got %v", ctx.Err())
+	}
+}
+
+func TestFetchAppTokenContextCancelled(t *testing.T) {
+	var (
+		requests    int
+		server      = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { requests++ }))
+		c           = getTestClient(server)
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+	defer server.Close()
+	cancel()
+	c.SetUser(nil)
+	req, _ := http.NewRequest("GET", server.URL+"/1.1/search/tweets.json?q=go", nil)
+	if _, err := c.SendRequestContext(ctx, req); err == nil {
+		t.Fatalf("Expected an error fetching a token with a cancelled context")
+	}
+	if requests != 0 || c.AppToken != nil {
+		t.Errorf("Expected no token to be fetched")
+	}
+}
+
+func TestParseContextCancelled(t *testing.T) {
+	var (
+		resp        = (*APIResponse)(getResponse(200, `{"id_str":"1234"}`))
+		ctx, cancel = context.WithCancel(context.Background())
+		err         error
+	)
+	cancel()
+	if err = resp.ParseContext(ctx, &Tweet{}); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFetchAppTokenErrors(t *testing.T) {
+	var body, status = "", 200
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	var (
+		c   = getHostTestClient(server)
+		err error
+	)
+	body = `{"token_type":"mac","access_token":"token"}`
+	if err = c.FetchAppToken(); err == nil || c.AppToken != nil {
+		t.Errorf("Expected error for a non-bearer token, got %v", err)
+	}
+	body = `{"unexpected":true}`
+	if err = c.FetchAppToken(); err == nil || c.AppToken != nil {
+		t.Errorf("Expected error for a missing token, got %v", err)
+	}
+	status, body = 403, `{"errors":[{"code":99,"message":"Unable to verify your credentials"}]}`
+	if err = c.FetchAppToken(); err == nil {
+		t.Fatalf("Expected error for a 403 response")
+	}
+	if errs, ok := err.(Errors); !ok || !errs.HasCode(99) {
+		t.Errorf("Expected Errors with code 99, got %v", err)
+	}
+}
+
+func TestInvalidateAppToken(t *testing.T) {
+	var form string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PATH_OAUTH2_INVALIDATE_TOKEN || !strings.HasPrefix(r.Header.Get("Authorization"), "Basic ") {
+			t.Errorf("Unexpected request %v %v", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		r.ParseForm()
+		form = r.Form.Get("access_token")
+		fmt.Fprint(w, `{"access_token":"apptoken"}`)
+	}))
+	defer server.Close()
+
+	var c = getHostTestClient(server)
+	c.SetAppToken("apptoken")
+	if err := c.InvalidateAppToken(); err != nil {
+		t.Fatalf("InvalidateAppToken returned error: %v", err)
+	}
+	if form != "apptoken" || c.GetAppToken() != "" {
+		t.Errorf("Token not invalidated: sent %v, have %v", form, c.GetAppToken())
+	}
+}
+
+func TestSendRequestRefetchesInvalidAppToken(t *testing.T) {
+	var (
+		fetches int
+		sent    []string
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == PATH_OAUTH2_APP_TOKEN {
+			fetches++
+			fmt.Fprint(w, `{"token_type":"bearer","access_token":"fresh"}`)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		sent = append(sent, string(body))
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(401)
+			fmt.Fprint(w, `{"errors":[{"code":89,"message":"Invalid or expired token."}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"id_str":"1"}`)
+	}))
+	defer server.Close()
+
+	var (
+		c     = getHostTestClient(server)
+		req   *http.Request
+		resp  *APIResponse
+		tweet = Tweet{}
+		err   error
+	)
+	c.SetUser(nil)
+	c.SetAppToken("stale")
+	req, _ = http.NewRequest("POST", "/1.1/statuses/lookup.json", strings.NewReader("id=1"))
+	if resp, err = c.SendRequest(req); err != nil {
+		t.Fatalf("SendRequest returned error: %v", err)
+	}
+	if err = resp.Parse(&tweet); err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if fetches != 1 || c.GetAppToken() != "fresh" {
+		t.Errorf("Expected one token fetch, got %v", fetches)
+	}
+	if len(sent) != 2 || sent[1] != "id=1" {
+		t.Errorf("Request body not replayed: %q", sent)
+	}
+
+	// Only one retry is attempted.
+	c.SetAppToken("stale")
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == PATH_OAUTH2_APP_TOKEN {
+			fmt.Fprint(w, `{"token_type":"bearer","access_token":"also_stale"}`)
+			return
+		}
+		w.WriteHeader(401)
+		fmt.Fprint(w, `{"errors":[{"code":89,"message":"Invalid or expired token."}]}`)
+	})
+	req, _ = http.NewRequest("GET", "/1.1/statuses/show.json?id=1", nil)
+	if resp, err = c.SendRequest(req); err != nil {
+		t.Fatalf("SendRequest returned error: %v", err)
+	}
+	if errs, ok := resp.Parse(&tweet).(Errors); !ok || !errs.HasCode(ERROR_INVALID_TOKEN) {
+		t.Errorf("Expected the invalid token error to be returned")
+	}
+}
+
+func TestConcurrentAppTokenFetch(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		fetches int
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == PATH_OAUTH2_APP_TOKEN {
+			mu.Lock()
+			fetches++
+			mu.Unlock()
+			time.Sleep(50 * time.Millisecond)
+			fmt.Fprint(w, `{"token_type":"bearer","access_token":"apptoken"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer apptoken" {
+			t.Errorf("Unexpected authorization %v", r.Header.Get("Authorization"))
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	var (
+		c  = getHostTestClient(server)
+		wg sync.WaitGroup
+	)
+	c.SetUser(nil)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", "/1.1/search/tweets.json?q=go", nil)
+			resp, err := c.SendRequest(req)
+			if err != nil {
+				t.Errorf("Unexpected error sending request: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if fetches != 1 {
+		t.Errorf("Expected a single token fetch, got %v", fetches)
+	}
+}
+
+func TestWithUser(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(H_LIMIT, "900")
+		w.Header().Set(H_LIMIT_REMAIN, "899")
+		w.Header().Set(H_LIMIT_RESET, "2000000000")
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	var (
+		c     = getHostTestClient(server)
+		users = []*oauth1a.UserConfig{
+			oauth1a.NewAuthorizedConfig("alice", "secret"),
+			oauth1a.NewAuthorizedConfig("bob", "secret"),
+		}
+		views []*Client
+		wg    sync.WaitGroup
+	)
+	for _, user := range users {
+		views = append(views, c.WithUser(user))
+	}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(view *Client) {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", "/1.1/statuses/home_timeline.json", nil)
+			resp, err := view.SendRequest(req)
+			if err != nil {
+				t.Errorf("Unexpected error sending request: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}(views[i%2])
+	}
+	wg.Wait()
+	if c.User.AccessTokenKey != "token" {
+		t.Errorf("WithUser changed the parent's user")
+	}
+	for i, view := range views {
+		if _, ok := c.RateLimits.Remaining("user:"+users[i].AccessTokenKey, PATH_HOME_TIMELINE); !ok {
+			t.Errorf("Limits for %v not shared with parent", users[i].AccessTokenKey)
+		}
+		view.SetAppToken("shared")
+	}
+	if c.GetAppToken() != "shared" {
+		t.Errorf("App token not shared with parent")
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Records HTTP interactions with the Twitter API to files and replays them,
+// so that tests of code built on twittergo run without a network.
+//
+// Record once against the real API:
+//
+//	c, err := cassette.Load("testdata/timeline.json", cassette.MODE_RECORD)
+//	c.Wrap(client)
+//	// ... make requests ...
+//	err = c.Save()
+//
+// then replay in tests with MODE_REPLAY.  Credentials are scrubbed from
+// recorded requests and responses.
+package cassette
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"github.com/kurrik/twittergo"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode determines whether a cassette sends requests or replays them.
+type Mode int
+
+const (
+	// Replay recorded responses, failing requests which were not recorded.
+	MODE_REPLAY Mode = iota
+	// Send every request and record it, replacing the cassette's contents.
+	MODE_RECORD
+	// Replay recorded responses, sending and recording requests which were
+	// not recorded.
+	MODE_RECORD_MISSING
+)
+
+// A recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// A recorded response.  Bodies which are not valid UTF-8 are kept in
+// BodyBytes instead of Body.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBytes  []byte      `json:"body_bytes,omitempty"`
+}
+
+// A request and the response it received.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Query parameters which carry credentials, and so are scrubbed and
+// ignored when matching.
+var authParams = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"client_secret": true,
+	"code_verifier": true,
+}
+
+// Cassette is an http.RoundTripper which records and replays interactions.
+// Recording reads each response in full, so streams cannot be recorded.
+// It is safe for concurrent use.
+type Cassette struct {
+	Path string
+	Mode Mode
+	// Sends requests when recording.  Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+	changed      bool
+}
+
+// Loads the cassette at path.  The file must exist unless mode is
+// MODE_RECORD or MODE_RECORD_MISSING.
+func Load(path string, mode Mode) (c *Cassette, err error) {
+	var b []byte
+	c = &Cassette{Path: path, Mode: mode}
+	if mode == MODE_RECORD {
+		return
+	}
+	if b, err = ioutil.ReadFile(path); err != nil {
+		if os.IsNotExist(err) && mode == MODE_RECORD_MISSING {
+			return c, nil
+		}
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &c.interactions); err != nil {
+		return nil, fmt.Errorf("Could not parse cassette %v: %v", path, err)
+	}
+	c.used = make([]bool, len(c.interactions))
+	return
+}
+
+// Sends the client's requests through the cassette.  When recording, the
+// requests are sent with the client's existing transport unless Transport
+// is set.
+func (c *Cassette) Wrap(client *twittergo.Client) {
+	var httpClient = &http.Client{}
+	if client.HttpClient != nil {
+		*httpClient = *client.HttpClient
+	}
+	if c.Transport == nil {
+		c.Transport = httpClient.Transport
+	}
+	httpClient.Transport = c
+	client.HttpClient = httpClient
+}
+
+// Returns the recorded interactions.
+func (c *Cassette) Interactions() []*Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Interaction(nil), c.interactions...)
+}
+
+// Writes the cassette to Path if anything was recorded.
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.changed {
+		return nil
+	}
+	b, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(c.Path, append(b, '\n'), 0644); err != nil {
+		return err
+	}
+	c.changed = false
+	return nil
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	if c.Mode != MODE_RECORD {
+		if i := c.find(req); i != nil {
+			closeBody(req)
+			return i.Response.httpResponse(req), nil
+		}
+		if c.Mode == MODE_REPLAY {
+			closeBody(req)
+			return nil, fmt.Errorf("Cassette %v has no response for %v %v", c.Path, req.Method, req.URL.Path)
+		}
+	}
+	return c.record(req)
+}
+
+// Closes the body of a request which will not be sent, as RoundTrip must.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// Returns the first unused interaction matching req, or the last used one
+// if all matching interactions have been replayed.
+func (c *Cassette) find(req *http.Request) *Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var (
+		key  = matchKey(req.Method, req.URL)
+		last = -1
+	)
+	for i, in := range c.interactions {
+		u, err := url.Parse(in.Request.URL)
+		if err != nil || matchKey(in.Request.Method, u) != key {
+			continue
+		}
+		if !c.used[i] {
+			c.used[i] = true
+			return in
+		}
+		last = i
+	}
+	if last < 0 {
+		return nil
+	}
+	return c.interactions[last]
+}
+
+// Returns the method, path and non-auth query parameters of a request.
+func matchKey(method string, u *url.URL) string {
+	var query = url.Values{}
+	for name, values := range u.Query() {
+		if !strings.HasPrefix(name, "oauth_") && !authParams[name] {
+			query[name] = values
+		}
+	}
+	return method + " " + u.Path + "?" + query.Encode()
+}
+
+// Sends req and records the scrubbed interaction.
+func (c *Cassette) record(req *http.Request) (resp *http.Response, err error) {
+	var (
+		transport = c.Transport
+		reqBody   []byte
+		respBody  []byte
+	)
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if req.Body != nil {
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return
+		}
+		req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+	if resp, err = transport.RoundTrip(req); err != nil {
+		return
+	}
+	respBody, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	// Decompressed so that the body can be scrubbed.
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		var zr *gzip.Reader
+		if zr, err = gzip.NewReader(bytes.NewReader(respBody)); err != nil {
+			return nil, err
+		}
+		if respBody, err = ioutil.ReadAll(zr); err != nil {
+			return nil, err
+		}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = int64(len(respBody))
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	var (
+		u  = *req.URL
+		in = &Interaction{
+			Request: Request{
+				Method: req.Method,
+				Header: twittergo.RedactHeader(req.Header),
+				Body:   scrubBody(reqBody),
+			},
+			Response: Response{
+				StatusCode: resp.StatusCode,
+				Header:     twittergo.RedactHeader(resp.Header),
+			},
+		}
+	)
+	u.RawQuery = scrubQuery(u.Query())
+	in.Request.URL = u.String()
+	if utf8.Valid(respBody) {
+		in.Response.Body = twittergo.Redact(string(respBody))
+	} else {
+		in.Response.BodyBytes = respBody
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, in)
+	c.used = append(c.used, true)
+	c.changed = true
+	return resp, nil
+}
+
+// Replaces the values of credential parameters.
+func scrubQuery(query url.Values) string {
+	for name := range query {
+		if strings.HasPrefix(name, "oauth_") || authParams[name] {
+			query.Set(name, twittergo.REDACTED)
+		}
+	}
+	return query.Encode()
+}
+
+func scrubBody(b []byte) string {
+	if !utf8.Valid(b) {
+		return fmt.Sprintf("[%d bytes of binary data]", len(b))
+	}
+	return twittergo.Redact(string(b))
+}
+
+// Returns the recorded response as a response to req.
+func (r *Response) httpResponse(req *http.Request) *http.Response {
+	var body = r.BodyBytes
+	if body == nil {
+		body = []byte(r.Body)
+	}
+	var header = http.Header{}
+	for name, values := range r.Header {
+		header[name] = append([]string(nil), values...)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassette
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"github.com/kurrik/oauth1a"
+	"github.com/kurrik/twittergo"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func getTestClient(server *httptest.Server) *twittergo.Client {
+	var (
+		config = &oauth1a.ClientConfig{
+			ConsumerKey:    "consumer_key_value",
+			ConsumerSecret: "consumer_secret_value",
+		}
+		user = oauth1a.NewAuthorizedConfig("token_value", "token_secret_value")
+		c    = twittergo.NewClient(config, user)
+	)
+	c.Host = strings.TrimPrefix(server.URL, "https://")
+	c.HttpClient = server.Client()
+	return c
+}
+
+func send(t *testing.T, c *twittergo.Client, method string, path string) map[string]interface{} {
+	req, _ := http.NewRequest(method, path, nil)
+	resp, err := c.SendRequest(req)
+	if err != nil {
+		t.Fatalf("Unexpected error sending %v: %v", path, err)
+	}
+	var out map[string]interface{}
+	if err = resp.Parse(&out); err != nil {
+		t.Fatalf("Unexpected error parsing %v: %v", path, err)
+	}
+	return out
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	var count = 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.Header().Set(twittergo.H_LIMIT, "180")
+		w.Header().Set(twittergo.H_LIMIT_REMAIN, fmt.Sprint(180-count))
+		w.Header().Set(twittergo.H_LIMIT_RESET, "2000000000")
+		if r.URL.Path == "/1.1/users/show.json" {
+			// Compressed responses are stored decompressed.
+			w.Header().Set("Content-Encoding", "gzip")
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			fmt.Fprintf(zw, `{"screen_name":%q}`, r.URL.Query().Get("screen_name"))
+			zw.Close()
+			w.Write(buf.Bytes())
+			return
+		}
+		fmt.Fprintf(w, `{"q":%q,"count":%v,"access_token":"leaked_token"}`, r.URL.Query().Get("q"), count)
+	}))
+
+	var path = filepath.Join(t.TempDir(), "cassette.json")
+	c, err := Load(path, MODE_RECORD)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	var client = getTestClient(server)
+	c.Wrap(client)
+	send(t, client, "GET", "/1.1/search/tweets.json?q=go")
+	send(t, client, "GET", "/1.1/search/tweets.json?q=go")
+	send(t, client, "GET", "/1.1/search/tweets.json?q=rust")
+	if got := send(t, client, "GET", "/1.1/users/show.json?screen_name=kurrik"); got["screen_name"] != "kurrik" {
+		t.Errorf("Unexpected recorded response %v", got)
+	}
+	if err = c.Save(); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	server.Close()
+
+	b, _ := ioutil.ReadFile(path)
+	for _, secret := range []string{"consumer_key_value", "token_value", "leaked_token"} {
+		if bytes.Contains(b, []byte(secret)) {
+			t.Errorf("Cassette contains %v:\n%s", secret, b)
+		}
+	}
+	var recorded []Interaction
+	if err = json.Unmarshal(b, &recorded); err != nil || len(recorded) != 4 {
+		t.Fatalf("Could not decode cassette: %v %v", len(recorded), err)
+	}
+	for _, in := range recorded {
+		var auth = in.Request.Header.Get("Authorization")
+		if !strings.Contains(auth, `oauth_signature="`+twittergo.REDACTED+`"`) {
+			t.Errorf("Signature not redacted in Authorization header %v", auth)
+		}
+	}
+
+	if c, err = Load(path, MODE_REPLAY); err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	client = getTestClient(server)
+	c.Wrap(client)
+	// Interactions replay in order, ignoring the order of parameters and
+	// the auth parameters.
+	if got := send(t, client, "GET", "/1.1/search/tweets.json?q=rust&oauth_token=other"); got["q"] != "rust" {
+		t.Errorf("Unexpected replayed response %v", got)
+	}
+	for _, expected := range []float64{1, 2, 2} {
+		if got := send(t, client, "GET", "/1.1/search/tweets.json?q=go"); got["count"] != expected {
+			t.Errorf("Expected count %v, got %v", expected, got["count"])
+		}
+	}
+	if got := send(t, client, "GET", "/1.1/users/show.json?screen_name=kurrik"); got["screen_name"] != "kurrik" {
+		t.Errorf("Unexpected replayed response %v", got)
+	}
+	if left, ok := client.RateLimitRemaining("/1.1/search/tweets.json"); !ok || left != 178 {
+		t.Errorf("Expected replayed rate limit headers, got %v %v", left, ok)
+	}
+	req, _ := http.NewRequest("GET", "/1.1/search/tweets.json?q=java", nil)
+	if _, err = client.SendRequest(req); err == nil {
+		t.Errorf("Expected error for request missing from cassette")
+	}
+}
+
+// Records whether it was closed.
+type trackingBody struct {
+	*strings.Reader
+	closed bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestReplayClosesRequestBody(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "cassette.json")
+	ioutil.WriteFile(path, []byte(`[{"request":{"method":"POST","url":"https://api.twitter.com/1.1/statuses/update.json"},"response":{"status_code":200,"body":"{}"}}]`), 0644)
+	c, err := Load(path, MODE_REPLAY)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	for _, path := range []string{"/1.1/statuses/update.json", "/1.1/statuses/destroy.json"} {
+		var body = &trackingBody{Reader: strings.NewReader("status=hi")}
+		req, _ := http.NewRequest("POST", "https://api.twitter.com"+path, body)
+		if resp, err := c.RoundTrip(req); err == nil {
+			resp.Body.Close()
+		}
+		if !body.closed {
+			t.Errorf("Request body for %v was not closed", path)
+		}
+	}
+}
@@ -0,0 +1,881 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittertest
+
+import (
+	"fmt"
+	"github.com/kurrik/twittergo"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// Longest Tweet the server accepts, in characters.
+	MAX_TWEET_LENGTH = 280
+	// Size of the media the server accepts through the simple upload.
+	MAX_SIMPLE_UPLOAD = 5 * 1024 * 1024
+)
+
+// Returns the endpoints the server implements, keyed by
+// twittergo.EndpointPath.
+func routes() map[string]route {
+	return map[string]route{
+		"/1.1/account/verify_credentials.json": {"GET", true, (*Server).verifyCredentials},
+		twittergo.PATH_UPDATE_STATUS:           {"POST", true, (*Server).updateStatus},
+		"/1.1/statuses/destroy/:id.json":       {"POST", true, (*Server).destroyStatus},
+		"/1.1/statuses/show/:id.json":          {"GET", false, (*Server).showStatus},
+		"/1.1/statuses/show.json":              {"GET", false, (*Server).showStatus},
+		twittergo.PATH_USER_TIMELINE:           {"GET", false, (*Server).userTimeline},
+		twittergo.PATH_HOME_TIMELINE:           {"GET", true, (*Server).homeTimeline},
+		twittergo.PATH_MENTIONS_TIMELINE:       {"GET", true, (*Server).mentionsTimeline},
+		twittergo.PATH_SEARCH_TWEETS:           {"GET", false, (*Server).search},
+		twittergo.PATH_SHOW_USER:               {"GET", false, (*Server).showUser},
+		"/1.1/lists/create.json":               {"POST", true, (*Server).createList},
+		"/1.1/lists/destroy.json":              {"POST", true, (*Server).destroyList},
+		"/1.1/lists/show.json":                 {"GET", false, (*Server).showList},
+		"/1.1/lists/list.json":                 {"GET", false, (*Server).listLists},
+		twittergo.PATH_LISTS_OWNERSHIPS:        {"GET", false, (*Server).listOwnerships},
+		twittergo.PATH_LISTS_MEMBERS:           {"GET", false, (*Server).listMembers},
+		"/1.1/lists/members/create.json":       {"POST", true, (*Server).addListMember},
+		"/1.1/lists/members/destroy.json":      {"POST", true, (*Server).removeListMember},
+		twittergo.PATH_LISTS_STATUSES:          {"GET", false, (*Server).listStatuses},
+		"/1.1/friendships/create.json":         {"POST", true, (*Server).follow},
+		"/1.1/friendships/destroy.json":        {"POST", true, (*Server).unfollow},
+		"/1.1/friendships/show.json":           {"GET", false, (*Server).showFriendship},
+		twittergo.PATH_FRIENDS_IDS:             {"GET", false, (*Server).friendIds},
+		twittergo.PATH_FOLLOWERS_IDS:           {"GET", false, (*Server).followerIds},
+		twittergo.PATH_MEDIA_UPLOAD:            {"POST", true, (*Server).uploadMedia},
+	}
+}
+
+// Adds the numeric path segments of a request to params as "id", e.g. the
+// 123 of /1.1/statuses/show/123.json.
+func pathParams(path string, params url.Values) {
+	for _, part := range strings.Split(path, "/")[2:] {
+		var name = strings.TrimSuffix(part, ".json")
+		if name != "" && strings.Trim(name, "0123456789") == "" {
+			params.Set("id", name)
+		}
+	}
+}
+
+// Returns the fields of a media upload, with the uploaded file, if any, as
+// the "media" value.
+func mediaParams(r *http.Request) url.Values {
+	var params = url.Values{}
+	for k, v := range r.Form {
+		params[k] = v
+	}
+	if r.MultipartForm == nil {
+		return params
+	}
+	if files := r.MultipartForm.File["media"]; len(files) > 0 {
+		if f, err := files[0].Open(); err == nil {
+			b, _ := ioutil.ReadAll(f)
+			f.Close()
+			params.Set("media", string(b))
+		}
+	}
+	return params
+}
+
+func parseId(params url.Values, name string) (int64, bool) {
+	var id, err = strconv.ParseInt(params.Get(name), 10, 64)
+	return id, err == nil && id > 0
+}
+
+// Returns the count parameter, bounded to max.
+func parseCount(params url.Values, def int, max int) int {
+	var count, err = strconv.Atoi(params.Get("count"))
+	if err != nil || count <= 0 {
+		return def
+	}
+	if count > max {
+		return max
+	}
+	return count
+}
+
+func parseBool(params url.Values, name string) bool {
+	switch strings.ToLower(params.Get(name)) {
+	case "1", "t", "true":
+		return true
+	}
+	return false
+}
+
+// Returns the user identified by user_id or screen_name, or the
+// parameters prefixed by prefix, e.g. "owner_".  The caller must hold mu.
+func (s *Server) findUser(params url.Values, prefix string) *user {
+	if id, ok := parseId(params, prefix+"user_id"); ok {
+		return s.userById(id)
+	}
+	if id, ok := parseId(params, prefix+"id"); ok && prefix != "" {
+		return s.userById(id)
+	}
+	var name = strings.TrimPrefix(params.Get(prefix+"screen_name"), "@")
+	for _, u := range s.users {
+		if name != "" && strings.EqualFold(u.ScreenName, name) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) userById(id int64) *user {
+	for _, u := range s.users {
+		if u.Id == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) tweetById(id int64) *tweet {
+	for _, t := range s.tweets {
+		if t.Id == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// Returns a copy of u with its counts filled in.  The caller must hold mu.
+func (s *Server) renderUser(u *user) *twittergo.UserV1 {
+	var out = u.UserV1
+	out.FollowersCount = 0
+	out.FriendsCount = int64(len(s.follows[u.Id]))
+	out.StatusesCount = 0
+	out.ListedCount = 0
+	for _, following := range s.follows {
+		if following[u.Id] {
+			out.FollowersCount++
+		}
+	}
+	for _, t := range s.tweets {
+		if t.userId == u.Id {
+			out.StatusesCount++
+		}
+	}
+	for _, l := range s.lists {
+		for _, id := range l.members {
+			if id == u.Id {
+				out.ListedCount++
+			}
+		}
+	}
+	return &out
+}
+
+// Returns a copy of t with its author, unless trimUser is set, in which
+// case only the author's id is included.  The caller must hold mu.
+func (s *Server) renderTweet(t *tweet, trimUser bool) twittergo.TweetV1 {
+	var out = t.TweetV1
+	if trimUser {
+		out.User = &twittergo.UserV1{Id: t.userId, IdStr: strconv.FormatInt(t.userId, 10)}
+	} else {
+		out.User = s.renderUser(s.userById(t.userId))
+	}
+	return out
+}
+
+// Returns the Tweets matching include, newest first, filtered by the
+// since_id, max_id, count and exclude_replies parameters.  The caller must
+// hold mu.
+func (s *Server) timeline(params url.Values, def int, max int, include func(t *tweet) bool) []twittergo.TweetV1 {
+	var (
+		out        = []twittergo.TweetV1{}
+		count      = parseCount(params, def, max)
+		sinceId, _ = parseId(params, "since_id")
+		maxId, _   = parseId(params, "max_id")
+		noReplies  = parseBool(params, "exclude_replies")
+		trimUser   = parseBool(params, "trim_user")
+	)
+	for i := len(s.tweets) - 1; i >= 0 && len(out) < count; i-- {
+		var t = s.tweets[i]
+		switch {
+		case t.Id <= sinceId:
+		case maxId != 0 && t.Id > maxId:
+		case noReplies && t.InReplyToStatusId != nil:
+		case include(t):
+			out = append(out, s.renderTweet(t, trimUser))
+		}
+	}
+	return out
+}
+
+// Returns a page of ids, starting at the offset given by the cursor
+// parameter, and the cursored response to return it in.
+func cursorPage(params url.Values, ids []int64, def int, max int) (page []int64, body map[string]interface{}) {
+	var (
+		count     = parseCount(params, def, max)
+		start, _  = strconv.Atoi(params.Get("cursor"))
+		next      = 0
+		previous  = 0
+		remaining int
+	)
+	if start < 0 || start > len(ids) {
+		start = 0
+	}
+	if remaining = len(ids) - start; remaining > count {
+		next = start + count
+		remaining = count
+	}
+	if start > 0 {
+		previous = -start
+	}
+	body = map[string]interface{}{
+		"next_cursor":         next,
+		"next_cursor_str":     strconv.Itoa(next),
+		"previous_cursor":     previous,
+		"previous_cursor_str": strconv.Itoa(previous),
+	}
+	return ids[start : start+remaining], body
+}
+
+func (s *Server) verifyCredentials(c caller, params url.Values) (int, interface{}) {
+	return http.StatusOK, s.renderUser(c.user)
+}
+
+var (
+	hashtagPattern = regexp.MustCompile(`#(\w+)`)
+	mentionPattern = regexp.MustCompile(`@(\w+)`)
+)
+
+// Returns the hashtags and mentions of known users in text.  Indices count
+// characters, not bytes.  The caller must hold mu.
+func (s *Server) entities(text string) *twittergo.EntitiesV1 {
+	var (
+		out     = &twittergo.EntitiesV1{}
+		indices = func(m []int) twittergo.Range {
+			return twittergo.Range{utf8.RuneCountInString(text[:m[0]]), utf8.RuneCountInString(text[:m[1]])}
+		}
+	)
+	for _, m := range hashtagPattern.FindAllStringSubmatchIndex(text, -1) {
+		out.Hashtags = append(out.Hashtags, twittergo.HashtagV1{
+			Indices: indices(m),
+			Text:    text[m[2]:m[3]],
+		})
+	}
+	for _, m := range mentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		if u := s.findUser(url.Values{"screen_name": {text[m[2]:m[3]]}}, ""); u != nil {
+			out.UserMentions = append(out.UserMentions, twittergo.UserMentionV1{
+				Id:         u.Id,
+				IdStr:      u.IdStr,
+				Indices:    indices(m),
+				Name:       u.Name,
+				ScreenName: u.ScreenName,
+			})
+		}
+	}
+	return out
+}
+
+func (s *Server) updateStatus(c caller, params url.Values) (int, interface{}) {
+	var (
+		text     = params.Get("status")
+		t        = &tweet{userId: c.user.Id}
+		attached []*media
+	)
+	if ids := params.Get("media_ids"); ids != "" {
+		for _, idStr := range strings.Split(ids, ",") {
+			id, _ := strconv.ParseInt(strings.TrimSpace(idStr), 10, 64)
+			if m := s.media[id]; m != nil && m.finalized {
+				attached = append(attached, m)
+				continue
+			}
+			return apiErrors(http.StatusBadRequest, ERROR_INVALID_MEDIA, "The media ID is invalid.")
+		}
+	}
+	if text == "" && len(attached) == 0 {
+		return apiErrors(http.StatusBadRequest, ERROR_MISSING_PARAMETER, "Missing required parameter: status.")
+	}
+	if utf8.RuneCountInString(text) > MAX_TWEET_LENGTH {
+		return apiErrors(http.StatusForbidden, ERROR_STATUS_TOO_LONG, "Tweet needs to be a bit shorter.")
+	}
+	for _, other := range s.tweets {
+		if other.userId == c.user.Id && other.Text == text && text != "" {
+			return apiErrors(http.StatusForbidden, ERROR_DUPLICATE_STATUS, "Status is a duplicate.")
+		}
+	}
+	if id, ok := parseId(params, "in_reply_to_status_id"); ok {
+		var parent = s.tweetById(id)
+		if parent == nil {
+			return apiErrors(http.StatusNotFound, ERROR_NO_STATUS_FOUND, "No status found with that ID.")
+		}
+		var (
+			author = s.userById(parent.userId)
+			idStr  = parent.IdStr
+		)
+		t.InReplyToStatusId = &parent.Id
+		t.InReplyToStatusIdStr = &idStr
+		t.InReplyToUserId = &author.Id
+		t.InReplyToUserIdStr = &author.IdStr
+		t.InReplyToScreenName = &author.ScreenName
+	}
+	t.Id = s.newId()
+	t.IdStr = strconv.FormatInt(t.Id, 10)
+	t.CreatedAt = time.Now().UTC().Format(time.RubyDate)
+	t.Text = text
+	t.FullText = text
+	t.DisplayTextRange = twittergo.Range{0, utf8.RuneCountInString(text)}
+	t.Source = `<a href="https://github.com/kurrik/twittergo" rel="nofollow">twittertest</a>`
+	t.Entities = s.entities(text)
+	for _, m := range attached {
+		var kind = "photo"
+		if strings.HasPrefix(m.mediaType, "video/") {
+			kind = "video"
+		} else if m.mediaType == "image/gif" {
+			kind = "animated_gif"
+		}
+		t.Entities.Media = append(t.Entities.Media, twittergo.MediaV1{
+			Id:            m.id,
+			IdStr:         strconv.FormatInt(m.id, 10),
+			Type:          kind,
+			MediaURL:      fmt.Sprintf("http://pbs.twimg.com/media/%v.jpg", m.id),
+			MediaURLHttps: fmt.Sprintf("https://pbs.twimg.com/media/%v.jpg", m.id),
+		})
+	}
+	if len(attached) > 0 {
+		t.ExtendedEntities = &twittergo.EntitiesV1{Media: t.Entities.Media}
+	}
+	s.tweets = append(s.tweets, t)
+	return http.StatusOK, s.renderTweet(t, parseBool(params, "trim_user"))
+}
+
+func (s *Server) destroyStatus(c caller, params url.Values) (int, interface{}) {
+	var id, _ = parseId(params, "id")
+	for i, t := range s.tweets {
+		if t.Id != id {
+			continue
+		}
+		if t.userId != c.user.Id {
+			return apiErrors(http.StatusForbidden, ERROR_CANNOT_DELETE_STATUS, "You may not delete another user's status.")
+		}
+		var out = s.renderTweet(t, false)
+		s.tweets = append(s.tweets[:i:i], s.tweets[i+1:]...)
+		return http.StatusOK, out
+	}
+	return apiErrors(http.StatusNotFound, ERROR_NO_STATUS_FOUND, "No status found with that ID.")
+}
+
+func (s *Server) showStatus(c caller, params url.Values) (int, interface{}) {
+	var id, _ = parseId(params, "id")
+	if t := s.tweetById(id); t != nil {
+		return http.StatusOK, s.renderTweet(t, parseBool(params, "trim_user"))
+	}
+	return apiErrors(http.StatusNotFound, ERROR_NO_STATUS_FOUND, "No status found with that ID.")
+}
+
+func (s *Server) userTimeline(c caller, params url.Values) (int, interface{}) {
+	var u = s.findUser(params, "")
+	if u == nil && c.user != nil && params.Get("user_id") == "" && params.Get("screen_name") == "" {
+		u = c.user
+	}
+	if u == nil {
+		return apiErrors(http.StatusNotFound, ERROR_PAGE_NOT_FOUND, "Sorry, that page does not exist.")
+	}
+	return http.StatusOK, s.timeline(params, 20, 200, func(t *tweet) bool {
+		return t.userId == u.Id
+	})
+}
+
+func (s *Server) homeTimeline(c caller, params url.Values) (int, interface{}) {
+	var following = s.follows[c.user.Id]
+	return http.StatusOK, s.timeline(params, 20, 200, func(t *tweet) bool {
+		return t.userId == c.user.Id || following[t.userId]
+	})
+}
+
+func (s *Server) mentionsTimeline(c caller, params url.Values) (int, interface{}) {
+	return http.StatusOK, s.timeline(params, 20, 200, func(t *tweet) bool {
+		for _, m := range t.Entities.UserMentions {
+			if m.Id == c.user.Id {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Returns true if every term of the query appears in the Tweet.  Terms of
+// the form from:name match the author's screen name.  The caller must hold
+// mu.
+func (s *Server) matches(t *tweet, terms []string) bool {
+	var text = strings.ToLower(t.Text)
+	for _, term := range terms {
+		if name := strings.TrimPrefix(term, "from:"); name != term {
+			if !strings.EqualFold(s.userById(t.userId).ScreenName, name) {
+				return false
+			}
+		} else if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) search(c caller, params url.Values) (int, interface{}) {
+	var (
+		query   = params.Get("q")
+		terms   = strings.Fields(strings.ToLower(query))
+		count   = parseCount(params, 15, 100)
+		results twittergo.SearchResultsV1
+		started = time.Now()
+	)
+	if len(terms) == 0 {
+		return apiErrors(http.StatusBadRequest, ERROR_QUERY_MISSING, "Query parameters are missing.")
+	}
+	// Request one extra Tweet to find out whether there is another page.
+	var page = url.Values{}
+	for k, v := range params {
+		page[k] = v
+	}
+	page.Set("count", strconv.Itoa(count+1))
+	results.Statuses = s.timeline(page, count+1, count+1, func(t *tweet) bool {
+		return s.matches(t, terms)
+	})
+	var meta = &results.SearchMetadata
+	meta.Query = url.QueryEscape(query)
+	meta.Count = count
+	meta.SinceId, _ = parseId(params, "since_id")
+	meta.SinceIdStr = strconv.FormatInt(meta.SinceId, 10)
+	if len(results.Statuses) > count {
+		results.Statuses = results.Statuses[:count]
+		var next = url.Values{
+			"q":                {query},
+			"count":            {strconv.Itoa(count)},
+			"include_entities": {"1"},
+			"max_id":           {strconv.FormatInt(results.Statuses[count-1].Id-1, 10)},
+		}
+		meta.NextResults = "?" + next.Encode()
+	}
+	if len(results.Statuses) > 0 {
+		meta.MaxId = results.Statuses[0].Id
+	}
+	meta.MaxIdStr = strconv.FormatInt(meta.MaxId, 10)
+	meta.RefreshURL = "?" + url.Values{
+		"q":                {query},
+		"since_id":         {meta.MaxIdStr},
+		"include_entities": {"1"},
+	}.Encode()
+	meta.CompletedIn = time.Since(started).Seconds()
+	return http.StatusOK, results
+}
+
+func (s *Server) showUser(c caller, params url.Values) (int, interface{}) {
+	if u := s.findUser(params, ""); u != nil {
+		return http.StatusOK, s.renderUser(u)
+	}
+	return apiErrors(http.StatusNotFound, ERROR_USER_NOT_FOUND, "User not found.")
+}
+
+// Returns the list identified by list_id, or by slug and owner_id or
+// owner_screen_name.  The caller must hold mu.
+func (s *Server) findList(params url.Values) *list {
+	var (
+		id, byId = parseId(params, "list_id")
+		owner    = s.findUser(params, "owner_")
+		slug     = params.Get("slug")
+	)
+	for _, l := range s.lists {
+		if byId && l.Id == id {
+			return l
+		}
+		if !byId && owner != nil && l.ownerId == owner.Id && l.Slug == slug {
+			return l
+		}
+	}
+	return nil
+}
+
+// Returns a copy of l with its owner and member count.  The caller must
+// hold mu.
+func (s *Server) renderList(l *list) twittergo.ListV1 {
+	var out = l.ListV1
+	out.MemberCount = int64(len(l.members))
+	out.User = s.renderUser(s.userById(l.ownerId))
+	return out
+}
+
+var listNotFound = []APIError{{ERROR_PAGE_NOT_FOUND, "Sorry, that page does not exist."}}
+
+func (s *Server) createList(c caller, params url.Values) (int, interface{}) {
+	var (
+		name = params.Get("name")
+		l    = &list{ownerId: c.user.Id}
+	)
+	if name == "" {
+		return apiErrors(http.StatusBadRequest, ERROR_MISSING_PARAMETER, "Missing required parameter: name.")
+	}
+	l.Id = s.newId()
+	l.IdStr = strconv.FormatInt(l.Id, 10)
+	l.Name = name
+	l.Slug = strings.ToLower(strings.Join(strings.Fields(name), "-"))
+	l.FullName = fmt.Sprintf("@%v/%v", c.user.ScreenName, l.Slug)
+	l.URI = fmt.Sprintf("/%v/lists/%v", c.user.ScreenName, l.Slug)
+	l.Mode = "public"
+	if params.Get("mode") == "private" {
+		l.Mode = "private"
+	}
+	l.Description = params.Get("description")
+	l.CreatedAt = time.Now().UTC().Format(time.RubyDate)
+	s.lists = append(s.lists, l)
+	return http.StatusOK, s.renderList(l)
+}
+
+func (s *Server) destroyList(c caller, params url.Values) (int, interface{}) {
+	var l = s.findList(params)
+	if l == nil || l.ownerId != c.user.Id {
+		return http.StatusNotFound, listNotFound
+	}
+	for i, other := range s.lists {
+		if other == l {
+			s.lists = append(s.lists[:i:i], s.lists[i+1:]...)
+		}
+	}
+	return http.StatusOK, s.renderList(l)
+}
+
+// Returns l if the caller may see it.
+func (s *Server) visibleList(c caller, l *list) *list {
+	if l == nil || (l.Mode == "private" && (c.user == nil || c.user.Id != l.ownerId)) {
+		return nil
+	}
+	return l
+}
+
+func (s *Server) showList(c caller, params url.Values) (int, interface{}) {
+	if l := s.visibleList(c, s.findList(params)); l != nil {
+		return http.StatusOK, s.renderList(l)
+	}
+	return http.StatusNotFound, listNotFound
+}
+
+// Returns the lists owned by the user identified by params, or the caller.
+// The caller must hold mu.
+func (s *Server) ownedLists(c caller, params url.Values) (lists []*list, ok bool) {
+	var u = s.findUser(params, "")
+	if u == nil && params.Get("user_id") == "" && params.Get("screen_name") == "" {
+		u = c.user
+	}
+	if u == nil {
+		return nil, false
+	}
+	for _, l := range s.lists {
+		if l.ownerId == u.Id && s.visibleList(c, l) != nil {
+			lists = append(lists, l)
+		}
+	}
+	return lists, true
+}
+
+func (s *Server) listLists(c caller, params url.Values) (int, interface{}) {
+	var lists, ok = s.ownedLists(c, params)
+	if !ok {
+		return apiErrors(http.StatusNotFound, ERROR_USER_NOT_FOUND, "User not found.")
+	}
+	var out = []twittergo.ListV1{}
+	for _, l := range lists {
+		out = append(out, s.renderList(l))
+	}
+	return http.StatusOK, out
+}
+
+func (s *Server) listOwnerships(c caller, params url.Values) (int, interface{}) {
+	var lists, ok = s.ownedLists(c, params)
+	if !ok {
+		return apiErrors(http.StatusNotFound, ERROR_USER_NOT_FOUND, "User not found.")
+	}
+	var ids []int64
+	for _, l := range lists {
+		ids = append(ids, l.Id)
+	}
+	var page, body = cursorPage(params, ids, 20, 1000)
+	var out = []twittergo.ListV1{}
+	for _, id := range page {
+		for _, l := range lists {
+			if l.Id == id {
+				out = append(out, s.renderList(l))
+			}
+		}
+	}
+	body["lists"] = out
+	return http.StatusOK, body
+}
+
+func (s *Server) listMembers(c caller, params url.Values) (int, interface{}) {
+	var l = s.visibleList(c, s.findList(params))
+	if l == nil {
+		return http.StatusNotFound, listNotFound
+	}
+	var page, body = cursorPage(params, l.members, 20, 5000)
+	var out = []*twittergo.UserV1{}
+	for _, id := range page {
+		out = append(out, s.renderUser(s.userById(id)))
+	}
+	body["users"] = out
+	return http.StatusOK, body
+}
+
+func (s *Server) addListMember(c caller, params url.Values) (int, interface{}) {
+	var (
+		l = s.findList(params)
+		u = s.findUser(params, "")
+	)
+	if l == nil || l.ownerId != c.user.Id {
+		return http.StatusNotFound, listNotFound
+	}
+	if u == nil {
+		return apiErrors(http.StatusNotFound, ERROR_USER_NOT_FOUND, "User not found.")
+	}
+	for _, id := range l.members {
+		if id == u.Id {
+			return http.StatusOK, s.renderList(l)
+		}
+	}
+	l.members = append(l.members, u.Id)
+	return http.StatusOK, s.renderList(l)
+}
+
+func (s *Server) removeListMember(c caller, params url.Values) (int, interface{}) {
+	var (
+		l = s.findList(params)
+		u = s.findUser(params, "")
+	)
+	if l == nil || l.ownerId != c.user.Id {
+		return http.StatusNotFound, listNotFound
+	}
+	if u == nil {
+		return apiErrors(http.StatusNotFound, ERROR_USER_NOT_FOUND, "User not found.")
+	}
+	for i, id := range l.members {
+		if id == u.Id {
+			l.members = append(l.members[:i:i], l.members[i+1:]...)
+			break
+		}
+	}
+	return http.StatusOK, s.renderList(l)
+}
+
+func (s *Server) listStatuses(c caller, params url.Values) (int, interface{}) {
+	var l = s.visibleList(c, s.findList(params))
+	if l == nil {
+		return http.StatusNotFound, listNotFound
+	}
+	var members = map[int64]bool{}
+	for _, id := range l.members {
+		members[id] = true
+	}
+	return http.StatusOK, s.timeline(params, 20, 200, func(t *tweet) bool {
+		return members[t.userId]
+	})
+}
+
+func (s *Server) follow(c caller, params url.Values) (int, interface{}) {
+	var u = s.findUser(params, "")
+	if u == nil {
+		return apiErrors(http.StatusNotFound, ERROR_USER_NOT_FOUND, "User not found.")
+	}
+	if u.Id == c.user.Id {
+		return apiErrors(http.StatusForbidden, ERROR_CANNOT_FOLLOW_SELF, "You can't follow yourself.")
+	}
+	if s.follows[c.user.Id] == nil {
+		s.follows[c.user.Id] = map[int64]bool{}
+	}
+	s.follows[c.user.Id][u.Id] = true
+	var (
+		out       = s.renderUser(u)
+		following = true
+	)
+	out.Following = &following
+	return http.StatusOK, out
+}
+
+func (s *Server) unfollow(c caller, params url.Values) (int, interface{}) {
+	var u = s.findUser(params, "")
+	if u == nil {
+		return apiErrors(http.StatusNotFound, ERROR_USER_NOT_FOUND, "User not found.")
+	}
+	delete(s.follows[c.user.Id], u.Id)
+	var (
+		out       = s.renderUser(u)
+		following = false
+	)
+	out.Following = &following
+	return http.StatusOK, out
+}
+
+func (s *Server) showFriendship(c caller, params url.Values) (int, interface{}) {
+	var (
+		source = s.findUser(params, "source_")
+		target = s.findUser(params, "target_")
+	)
+	if source == nil && params.Get("source_id") == "" && params.Get("source_screen_name") == "" {
+		source = c.user
+	}
+	if source == nil || target == nil {
+		return apiErrors(http.StatusNotFound, ERROR_USER_NOT_FOUND, "User not found.")
+	}
+	var side = func(u *user, following bool, followedBy bool) map[string]interface{} {
+		return map[string]interface{}{
+			"id":          u.Id,
+			"id_str":      u.IdStr,
+			"screen_name": u.ScreenName,
+			"following":   following,
+			"followed_by": followedBy,
+		}
+	}
+	var (
+		forward  = s.follows[source.Id][target.Id]
+		backward = s.follows[target.Id][source.Id]
+	)
+	return http.StatusOK, map[string]interface{}{
+		"relationship": map[string]interface{}{
+			"source": side(source, forward, backward),
+			"target": side(target, backward, forward),
+		},
+	}
+}
+
+// Returns a page of ids from the cursored ids endpoints.
+func (s *Server) idsPage(c caller, params url.Values, related func(u *user) []int64) (int, interface{}) {
+	var u = s.findUser(params, "")
+	if u == nil && params.Get("user_id") == "" && params.Get("screen_name") == "" {
+		u = c.user
+	}
+	if u == nil {
+		return apiErrors(http.StatusNotFound, ERROR_USER_NOT_FOUND, "User not found.")
+	}
+	var ids = related(u)
+	// Newest first in the real API; ids are assigned in order.
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+	var page, body = cursorPage(params, ids, 5000, 5000)
+	if parseBool(params, "stringify_ids") {
+		var out = []string{}
+		for _, id := range page {
+			out = append(out, strconv.FormatInt(id, 10))
+		}
+		body["ids"] = out
+	} else {
+		body["ids"] = append([]int64{}, page...)
+	}
+	return http.StatusOK, body
+}
+
+func (s *Server) friendIds(c caller, params url.Values) (int, interface{}) {
+	return s.idsPage(c, params, func(u *user) (ids []int64) {
+		for id := range s.follows[u.Id] {
+			ids = append(ids, id)
+		}
+		return
+	})
+}
+
+func (s *Server) followerIds(c caller, params url.Values) (int, interface{}) {
+	return s.idsPage(c, params, func(u *user) (ids []int64) {
+		for id, following := range s.follows {
+			if following[u.Id] {
+				ids = append(ids, id)
+			}
+		}
+		return
+	})
+}
+
+// Returns the media response for m.
+func (m *media) response() map[string]interface{} {
+	var out = map[string]interface{}{
+		"media_id":           m.id,
+		"media_id_string":    strconv.FormatInt(m.id, 10),
+		"size":               m.size,
+		"expires_after_secs": 86400,
+	}
+	if m.finalized && m.pending >= 0 {
+		var info = map[string]interface{}{"state": "succeeded", "progress_percent": 100}
+		if m.pending > 0 {
+			info = map[string]interface{}{"state": "in_progress", "check_after_secs": 0, "progress_percent": 50}
+		}
+		out["processing_info"] = info
+	}
+	return out
+}
+
+// Implements the simple upload and the INIT, APPEND, FINALIZE and STATUS
+// commands of the chunked upload.  Video and GIFs report processing as in
+// progress until the first STATUS command.
+func (s *Server) uploadMedia(c caller, params url.Values) (int, interface{}) {
+	var (
+		id, _ = parseId(params, "media_id")
+		m     = s.media[id]
+	)
+	switch params.Get("command") {
+	case "":
+		var data = params.Get("media")
+		if data == "" {
+			return apiErrors(http.StatusBadRequest, ERROR_MISSING_PARAMETER, "Missing required parameter: media.")
+		}
+		if len(data) > MAX_SIMPLE_UPLOAD {
+			return apiErrors(http.StatusBadRequest, ERROR_INVALID_MEDIA, "File size exceeds 5242880 bytes.")
+		}
+		m = &media{id: s.newId(), mediaType: "image/jpeg", size: int64(len(data)), finalized: true, pending: -1}
+		s.media[m.id] = m
+		return http.StatusOK, m.response()
+	case "INIT":
+		var total, err = strconv.ParseInt(params.Get("total_bytes"), 10, 64)
+		if err != nil || total <= 0 || params.Get("media_type") == "" {
+			return apiErrors(http.StatusBadRequest, ERROR_MISSING_PARAMETER, "Missing required parameter: total_bytes or media_type.")
+		}
+		m = &media{id: s.newId(), mediaType: params.Get("media_type"), category: params.Get("media_category"), total: total, pending: -1}
+		if strings.HasPrefix(m.mediaType, "video/") || m.mediaType == "image/gif" {
+			m.pending = 1
+		}
+		s.media[m.id] = m
+		return http.StatusAccepted, m.response()
+	}
+	if m == nil {
+		return apiErrors(http.StatusBadRequest, ERROR_INVALID_MEDIA, "The media ID is invalid.")
+	}
+	switch params.Get("command") {
+	case "APPEND":
+		if m.finalized {
+			return apiErrors(http.StatusBadRequest, ERROR_INVALID_MEDIA, "Segments may not be appended after FINALIZE.")
+		}
+		m.size += int64(len(params.Get("media")))
+		return http.StatusNoContent, nil
+	case "FINALIZE":
+		if m.size != m.total {
+			return apiErrors(http.StatusBadRequest, ERROR_INVALID_MEDIA, fmt.Sprintf("File size mismatch: expected %v bytes, received %v.", m.total, m.size))
+		}
+		m.finalized = true
+		return http.StatusCreated, m.response()
+	case "STATUS":
+		if !m.finalized || m.pending < 0 {
+			return apiErrors(http.StatusNotFound, ERROR_INVALID_MEDIA, "The media has no processing status.")
+		}
+		if m.pending > 0 {
+			m.pending--
+		}
+		return http.StatusOK, m.response()
+	}
+	return apiErrors(http.StatusBadRequest, ERROR_MISSING_PARAMETER, "Unknown command.")
+}
@@ -0,0 +1,471 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements a fake Twitter API for testing code built on twittergo.
+//
+//	server := twittertest.NewServer()
+//	defer server.Close()
+//	server.AddUser("kurrik", "Arne Roomann-Kurrik")
+//	client := server.NewClient("kurrik")
+//	tweet, err := client.UpdateStatus(ctx, twittergo.UpdateStatusParams{Status: "Hello"})
+//
+// The server keeps users, Tweets, lists, follows and uploaded media in
+// memory, enforces rate limits with the same headers and errors as the
+// real API, and can be told to fail requests.
+package twittertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kurrik/oauth1a"
+	"github.com/kurrik/twittergo"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Error codes returned by the server.
+// https://developer.twitter.com/en/support/twitter-api/error-troubleshooting
+const (
+	ERROR_QUERY_MISSING        = 25
+	ERROR_PAGE_NOT_FOUND       = 34
+	ERROR_MISSING_PARAMETER    = 38
+	ERROR_USER_NOT_FOUND       = 50
+	ERROR_RATE_LIMIT_EXCEEDED  = 88
+	ERROR_BAD_CREDENTIALS      = 99
+	ERROR_NO_STATUS_FOUND      = 144
+	ERROR_CANNOT_FOLLOW_SELF   = 158
+	ERROR_MISSING_GRANT_TYPE   = 170
+	ERROR_CANNOT_DELETE_STATUS = 183
+	ERROR_STATUS_TOO_LONG      = 186
+	ERROR_DUPLICATE_STATUS     = 187
+	ERROR_BAD_AUTHENTICATION   = 215
+	ERROR_CANNOT_ACCESS        = 220
+	ERROR_INVALID_MEDIA        = 324
+)
+
+const (
+	// Consumer credentials accepted by the server.
+	CONSUMER_KEY    = "consumer_key"
+	CONSUMER_SECRET = "consumer_secret"
+	// The app-only bearer token issued by the server.
+	APP_TOKEN = "app_token"
+)
+
+// An error in the API's {"errors":[...]} format.
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// The credentials of an account on the server.
+type Account struct {
+	Id                int64
+	ScreenName        string
+	AccessToken       string
+	AccessTokenSecret string
+}
+
+type user struct {
+	twittergo.UserV1
+	secret string
+}
+
+type tweet struct {
+	twittergo.TweetV1
+	userId int64
+}
+
+type list struct {
+	twittergo.ListV1
+	ownerId int64
+	members []int64
+}
+
+type media struct {
+	id        int64
+	mediaType string
+	category  string
+	total     int64
+	size      int64
+	finalized bool
+	// Number of STATUS requests before processing succeeds.
+	pending int
+}
+
+type window struct {
+	reset     time.Time
+	remaining int
+}
+
+type windowKey struct {
+	identity string
+	path     string
+}
+
+// Server is a fake API on an httptest TLS server.  Clients returned by
+// NewClient send API, upload and stream requests to it.  It is safe for
+// concurrent use.
+type Server struct {
+	*httptest.Server
+	// How long rate limit windows last.  Defaults to 15 minutes.
+	RateLimitWindow time.Duration
+
+	mu       sync.Mutex
+	nextId   int64
+	users    []*user
+	tokens   map[string]*user
+	tweets   []*tweet
+	lists    []*list
+	follows  map[int64]map[int64]bool
+	media    map[int64]*media
+	limits   map[string]int
+	windows  map[windowKey]*window
+	injected map[string][]injectedError
+	routes   map[string]route
+}
+
+type injectedError struct {
+	status int
+	errs   []APIError
+}
+
+// Identifies the caller of a request.  User is nil for app-only requests.
+type caller struct {
+	user     *user
+	identity string
+}
+
+type route struct {
+	method string
+	// Whether app-only auth is refused.
+	userOnly bool
+	handle   func(s *Server, c caller, params url.Values) (status int, body interface{})
+}
+
+// The limits the real API applies to each 15 minute window, for user auth.
+var DefaultRateLimits = map[string]int{
+	"/1.1/account/verify_credentials.json": 75,
+	twittergo.PATH_HOME_TIMELINE:           15,
+	twittergo.PATH_USER_TIMELINE:           900,
+	twittergo.PATH_SEARCH_TWEETS:           180,
+	twittergo.PATH_SHOW_USER:               900,
+	"/1.1/statuses/show/:id.json":          900,
+	"/1.1/lists/list.json":                 15,
+	"/1.1/lists/show.json":                 75,
+	twittergo.PATH_LISTS_STATUSES:          900,
+	twittergo.PATH_LISTS_MEMBERS:           900,
+	twittergo.PATH_LISTS_OWNERSHIPS:        15,
+	"/1.1/friendships/show.json":           180,
+	twittergo.PATH_FRIENDS_IDS:             15,
+	twittergo.PATH_FOLLOWERS_IDS:           15,
+}
+
+// Starts a server with no users and the default rate limits.
+func NewServer() *Server {
+	var s = &Server{
+		RateLimitWindow: 15 * time.Minute,
+		nextId:          1000000000000000000,
+		tokens:          map[string]*user{},
+		follows:         map[int64]map[int64]bool{},
+		media:           map[int64]*media{},
+		limits:          map[string]int{},
+		windows:         map[windowKey]*window{},
+		injected:        map[string][]injectedError{},
+		routes:          routes(),
+	}
+	for path, limit := range DefaultRateLimits {
+		s.limits[path] = limit
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Returns the host and port of the server, as used for Client.Host.
+func (s *Server) Host() string {
+	return strings.TrimPrefix(s.URL, "https://")
+}
+
+// Creates an account and returns its credentials.
+func (s *Server) AddUser(screenName string, name string) Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var (
+		id = s.newId()
+		u  = &user{secret: fmt.Sprintf("secret-%v", id)}
+	)
+	u.Id = id
+	u.IdStr = strconv.FormatInt(id, 10)
+	u.ScreenName = screenName
+	u.Name = name
+	u.CreatedAt = time.Now().UTC().Format(time.RubyDate)
+	u.DefaultProfile = true
+	u.DefaultProfileImage = true
+	s.users = append(s.users, u)
+	s.tokens[fmt.Sprintf("%v-token", id)] = u
+	return Account{
+		Id:                id,
+		ScreenName:        screenName,
+		AccessToken:       fmt.Sprintf("%v-token", id),
+		AccessTokenSecret: u.secret,
+	}
+}
+
+// Returns a client which sends requests to the server, signed as the user
+// with the supplied screen name, or with app-only auth if it is empty.
+func (s *Server) NewClient(screenName string) *twittergo.Client {
+	var (
+		config = &oauth1a.ClientConfig{
+			ConsumerKey:    CONSUMER_KEY,
+			ConsumerSecret: CONSUMER_SECRET,
+		}
+		userConfig *oauth1a.UserConfig
+	)
+	if screenName != "" {
+		s.mu.Lock()
+		for token, u := range s.tokens {
+			if strings.EqualFold(u.ScreenName, screenName) {
+				userConfig = oauth1a.NewAuthorizedConfig(token, u.secret)
+			}
+		}
+		s.mu.Unlock()
+		if userConfig == nil {
+			panic(fmt.Sprintf("twittertest: no user %v", screenName))
+		}
+	}
+	c, err := twittergo.NewClientWithOptions(config, userConfig,
+		twittergo.WithAPIHost(s.Host()),
+		twittergo.WithUploadHost(s.Host()),
+		twittergo.WithStreamHost(s.Host()),
+		twittergo.WithHTTPClient(s.Server.Client()))
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Sets the number of requests each user, and the app, may make to the
+// endpoint at path in each window.  Zero removes the limit.  Paths use
+// the form returned by twittergo.EndpointPath.
+func (s *Server) SetRateLimit(path string, limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if limit == 0 {
+		delete(s.limits, path)
+	} else {
+		s.limits[path] = limit
+	}
+	for key := range s.windows {
+		if key.path == path {
+			delete(s.windows, key)
+		}
+	}
+}
+
+// Starts new rate limit windows for every endpoint.
+func (s *Server) ResetRateLimits() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.windows = map[windowKey]*window{}
+}
+
+// Makes the next request to the endpoint at path fail with status and
+// errs.  Errors injected for the same path are returned in order.
+func (s *Server) InjectError(path string, status int, errs ...APIError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.injected[path] = append(s.injected[path], injectedError{status, errs})
+}
+
+// Returns every Tweet posted to the server, newest first.
+func (s *Server) Tweets() []twittergo.TweetV1 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []twittergo.TweetV1
+	for i := len(s.tweets) - 1; i >= 0; i-- {
+		out = append(out, s.renderTweet(s.tweets[i], false))
+	}
+	return out
+}
+
+// Returns a new ID.  The caller must hold mu.
+func (s *Server) newId() int64 {
+	s.nextId += 1 + s.nextId%7
+	return s.nextId
+}
+
+var oauthToken = regexp.MustCompile(`oauth_token="([^"]*)"`)
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		path     = twittergo.EndpointPath(r.URL.Path)
+		rt, ok   = s.routes[path]
+		params   url.Values
+		c        caller
+		status   int
+		body     interface{}
+		apiError *APIError
+	)
+	if r.URL.Path == twittergo.PATH_OAUTH2_APP_TOKEN {
+		s.serveAppToken(w, r)
+		return
+	}
+	if !ok {
+		writeErrors(w, http.StatusNotFound, APIError{ERROR_PAGE_NOT_FOUND, "Sorry, that page does not exist."})
+		return
+	}
+	// The media upload endpoint also accepts GET for the STATUS command.
+	if r.Method != rt.method && !(path == twittergo.PATH_MEDIA_UPLOAD && r.Method == "GET") {
+		writeErrors(w, http.StatusNotFound, APIError{ERROR_PAGE_NOT_FOUND, "Sorry, that page does not exist."})
+		return
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			writeErrors(w, http.StatusBadRequest, APIError{ERROR_MISSING_PARAMETER, err.Error()})
+			return
+		}
+	} else {
+		r.ParseForm()
+	}
+	params = r.Form
+	pathParams(r.URL.Path, params)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, apiError, status = s.authenticate(r); apiError != nil {
+		writeErrors(w, status, *apiError)
+		return
+	}
+	if rt.userOnly && c.user == nil {
+		writeErrors(w, http.StatusForbidden, APIError{ERROR_CANNOT_ACCESS, "Your credentials do not allow access to this resource."})
+		return
+	}
+	if !s.rateLimit(w, c, path) {
+		writeErrors(w, twittergo.STATUS_LIMIT, APIError{ERROR_RATE_LIMIT_EXCEEDED, "Rate limit exceeded"})
+		return
+	}
+	if queue := s.injected[path]; len(queue) > 0 {
+		s.injected[path] = queue[1:]
+		writeErrors(w, queue[0].status, queue[0].errs...)
+		return
+	}
+	if path == twittergo.PATH_MEDIA_UPLOAD {
+		// Upload files are not in r.Form.
+		params = mediaParams(r)
+	}
+	status, body = rt.handle(s, c, params)
+	if errs, ok := body.([]APIError); ok {
+		writeErrors(w, status, errs...)
+		return
+	}
+	writeJSON(w, status, body)
+}
+
+// Identifies the caller from the Authorization header.  The caller must
+// hold mu.
+func (s *Server) authenticate(r *http.Request) (c caller, err *APIError, status int) {
+	var auth = r.Header.Get("Authorization")
+	switch {
+	case strings.HasPrefix(auth, "OAuth "):
+		var m = oauthToken.FindStringSubmatch(auth)
+		if m == nil {
+			return c, &APIError{ERROR_BAD_AUTHENTICATION, "Bad Authentication data."}, http.StatusBadRequest
+		}
+		token, _ := url.QueryUnescape(m[1])
+		if c.user = s.tokens[token]; c.user == nil {
+			return c, &APIError{twittergo.ERROR_INVALID_TOKEN, "Invalid or expired token."}, http.StatusUnauthorized
+		}
+		c.identity = "user:" + c.user.IdStr
+	case auth == "Bearer "+APP_TOKEN:
+		c.identity = "app"
+	case strings.HasPrefix(auth, "Bearer "):
+		return c, &APIError{twittergo.ERROR_INVALID_TOKEN, "Invalid or expired token."}, http.StatusUnauthorized
+	default:
+		return c, &APIError{ERROR_BAD_AUTHENTICATION, "Bad Authentication data."}, http.StatusBadRequest
+	}
+	return
+}
+
+// Counts a request against the caller's limit for path, setting the rate
+// limit headers.  Returns false if the limit is exhausted.  The caller
+// must hold mu.
+func (s *Server) rateLimit(w http.ResponseWriter, c caller, path string) bool {
+	var limit, ok = s.limits[path]
+	if !ok {
+		return true
+	}
+	var (
+		key = windowKey{c.identity, path}
+		win = s.windows[key]
+		now = time.Now()
+	)
+	if win == nil || !now.Before(win.reset) {
+		var length = s.RateLimitWindow
+		if length <= 0 {
+			length = 15 * time.Minute
+		}
+		win = &window{reset: now.Add(length), remaining: limit}
+		s.windows[key] = win
+	}
+	var allowed = win.remaining > 0
+	if allowed {
+		win.remaining--
+	}
+	w.Header().Set(twittergo.H_LIMIT, strconv.Itoa(limit))
+	w.Header().Set(twittergo.H_LIMIT_REMAIN, strconv.Itoa(win.remaining))
+	w.Header().Set(twittergo.H_LIMIT_RESET, strconv.FormatInt(win.reset.Unix(), 10))
+	return allowed
+}
+
+// Issues APP_TOKEN for the server's consumer credentials.
+func (s *Server) serveAppToken(w http.ResponseWriter, r *http.Request) {
+	key, secret, ok := r.BasicAuth()
+	r.ParseForm()
+	if !ok || key != CONSUMER_KEY || secret != CONSUMER_SECRET {
+		writeErrors(w, http.StatusForbidden, APIError{ERROR_BAD_CREDENTIALS, "Unable to verify your credentials"})
+		return
+	}
+	if r.Form.Get("grant_type") != "client_credentials" {
+		writeErrors(w, http.StatusForbidden, APIError{ERROR_MISSING_GRANT_TYPE, "Missing required parameter: grant_type"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"token_type":   "bearer",
+		"access_token": APP_TOKEN,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeErrors(w http.ResponseWriter, status int, errs ...APIError) {
+	writeJSON(w, status, map[string][]APIError{"errors": errs})
+}
+
+// Returns an error response body.
+func apiErrors(status int, code int, message string) (int, interface{}) {
+	return status, []APIError{{code, message}}
+}
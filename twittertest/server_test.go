@@ -0,0 +1,286 @@
+// Copyright 2026 Arne Roomann-Kurrik
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twittertest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kurrik/twittergo"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func getTestServer() *Server {
+	var s = NewServer()
+	s.AddUser("kurrik", "Arne Roomann-Kurrik")
+	s.AddUser("gopher", "Gopher")
+	return s
+}
+
+func call(t *testing.T, c *twittergo.Client, method string, path string, params url.Values, out interface{}) error {
+	var (
+		body io.Reader
+		u    = fmt.Sprintf("https://%v%v", c.Host, path)
+	)
+	if method == "GET" {
+		u += "?" + params.Encode()
+	} else {
+		body = strings.NewReader(params.Encode())
+	}
+	req, _ := http.NewRequest(method, u, body)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	resp, err := c.SendRequest(req)
+	if err != nil {
+		t.Fatalf("Unexpected error sending %v: %v", path, err)
+	}
+	return resp.Parse(out)
+}
+
+func hasCode(err error, code int64) bool {
+	var errs, ok = err.(twittergo.Errors)
+	return ok && errs.HasCode(code)
+}
+
+func TestTweets(t *testing.T) {
+	var (
+		s      = getTestServer()
+		ctx    = context.Background()
+		kurrik = s.NewClient("kurrik")
+		gopher = s.NewClient("gopher")
+	)
+	defer s.Close()
+	first, err := kurrik.UpdateStatus(ctx, twittergo.UpdateStatusParams{Status: "Hello #golang @gopher"})
+	if err != nil {
+		t.Fatalf("UpdateStatus returned error: %v", err)
+	}
+	if first.User().ScreenName() != "kurrik" || len(first.Entities().Hashtags()) != 1 || len(first.Entities().UserMentions()) != 1 {
+		t.Errorf("Unexpected Tweet %v", first)
+	}
+	_, err = kurrik.UpdateStatus(ctx, twittergo.UpdateStatusParams{Status: "Hello #golang @gopher"})
+	if !hasCode(err, ERROR_DUPLICATE_STATUS) {
+		t.Errorf("Expected duplicate status error, got %v", err)
+	}
+	_, err = kurrik.UpdateStatus(ctx, twittergo.UpdateStatusParams{Status: strings.Repeat("x", 281)})
+	if !hasCode(err, ERROR_STATUS_TOO_LONG) {
+		t.Errorf("Expected status too long error, got %v", err)
+	}
+	reply, err := gopher.UpdateStatus(ctx, twittergo.UpdateStatusParams{Status: "@kurrik hi", InReplyToStatusId: first.Id()})
+	if err != nil {
+		t.Fatalf("UpdateStatus returned error: %v", err)
+	}
+	if v1, _ := reply.ToV1(); v1.InReplyToScreenName == nil || *v1.InReplyToScreenName != "kurrik" {
+		t.Errorf("Unexpected reply %v", reply)
+	}
+	for i := 0; i < 4; i++ {
+		kurrik.UpdateStatus(ctx, twittergo.UpdateStatusParams{Status: fmt.Sprintf("golang %v", i)})
+	}
+
+	timeline, err := kurrik.UserTimeline(ctx, twittergo.UserTimelineParams{ScreenName: "kurrik", Count: 3})
+	if err != nil || len(timeline) != 3 || timeline[0].Text() != "golang 3" {
+		t.Errorf("Unexpected timeline %v, %v", timeline, err)
+	}
+	timeline, _ = kurrik.UserTimeline(ctx, twittergo.UserTimelineParams{ScreenName: "gopher", ExcludeReplies: true})
+	if len(timeline) != 0 {
+		t.Errorf("Expected replies to be excluded, got %v", timeline)
+	}
+
+	var (
+		it    = twittergo.NewSearchIterator(gopher, twittergo.SearchParams{Query: "GOLANG", Count: 2})
+		found []uint64
+	)
+	for {
+		results, err := it.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Search returned error: %v", err)
+		}
+		for _, tweet := range results.Statuses() {
+			found = append(found, tweet.Id())
+		}
+	}
+	if len(found) != 5 || found[4] != first.Id() {
+		t.Errorf("Unexpected search results %v", found)
+	}
+
+	user, err := gopher.ShowUser(ctx, twittergo.ShowUserParams{ScreenName: "kurrik"})
+	if err != nil {
+		t.Fatalf("ShowUser returned error: %v", err)
+	}
+	if v1, _ := user.ToV1(); v1.Name != "Arne Roomann-Kurrik" || v1.StatusesCount != 5 {
+		t.Errorf("Unexpected user %v", user)
+	}
+	_, err = gopher.ShowUser(ctx, twittergo.ShowUserParams{ScreenName: "nobody"})
+	if !hasCode(err, ERROR_USER_NOT_FOUND) {
+		t.Errorf("Expected user not found error, got %v", err)
+	}
+}
+
+func TestListsAndFriendships(t *testing.T) {
+	var (
+		s      = getTestServer()
+		ctx    = context.Background()
+		kurrik = s.NewClient("kurrik")
+		list   twittergo.List
+		ids    []uint64
+	)
+	defer s.Close()
+	if err := call(t, kurrik, "POST", "/1.1/lists/create.json", url.Values{"name": {"Go People"}}, &list); err != nil {
+		t.Fatalf("Could not create list: %v", err)
+	}
+	if list.Slug() != "go-people" {
+		t.Errorf("Unexpected list %v", list)
+	}
+	for _, name := range []string{"gopher", "kurrik"} {
+		var params = url.Values{"list_id": {list.IdStr()}, "screen_name": {name}}
+		if err := call(t, kurrik, "POST", "/1.1/lists/members/create.json", params, &list); err != nil {
+			t.Fatalf("Could not add list member: %v", err)
+		}
+		if err := call(t, kurrik, "POST", "/1.1/friendships/create.json", url.Values{"screen_name": {name}}, nil); name == "kurrik" && !hasCode(err, ERROR_CANNOT_FOLLOW_SELF) {
+			t.Errorf("Expected error following self, got %v", err)
+		}
+	}
+	var (
+		members []string
+		it      = twittergo.NewCursorIterator(kurrik, twittergo.PATH_LISTS_MEMBERS, url.Values{
+			"slug":              {"go-people"},
+			"owner_screen_name": {"kurrik"},
+			"count":             {"1"},
+		})
+	)
+	err := it.EachUser(ctx, func(u twittergo.User) error {
+		members = append(members, u.ScreenName())
+		return nil
+	})
+	if err != nil || strings.Join(members, ",") != "gopher,kurrik" {
+		t.Errorf("Unexpected list members %v, %v", members, err)
+	}
+	it = twittergo.NewCursorIterator(kurrik, twittergo.PATH_FOLLOWERS_IDS, url.Values{"screen_name": {"gopher"}})
+	if err = it.EachID(ctx, func(id uint64) error { ids = append(ids, id); return nil }); err != nil || len(ids) != 1 {
+		t.Errorf("Unexpected follower ids %v, %v", ids, err)
+	}
+
+	s.NewClient("gopher").UpdateStatus(ctx, twittergo.UpdateStatusParams{Status: "In a list"})
+	var home twittergo.Timeline
+	if err = call(t, kurrik, "GET", twittergo.PATH_HOME_TIMELINE, url.Values{}, &home); err != nil || len(home) != 1 {
+		t.Errorf("Unexpected home timeline %v, %v", home, err)
+	}
+	var statuses twittergo.Timeline
+	if err = call(t, kurrik, "GET", twittergo.PATH_LISTS_STATUSES, url.Values{"list_id": {list.IdStr()}}, &statuses); err != nil || len(statuses) != 1 {
+		t.Errorf("Unexpected list statuses %v, %v", statuses, err)
+	}
+	call(t, kurrik, "POST", "/1.1/friendships/destroy.json", url.Values{"screen_name": {"gopher"}}, nil)
+	var relationship map[string]map[string]map[string]interface{}
+	call(t, kurrik, "GET", "/1.1/friendships/show.json", url.Values{"target_screen_name": {"gopher"}}, &relationship)
+	if relationship["relationship"]["source"]["following"] != false {
+		t.Errorf("Unexpected relationship %v", relationship)
+	}
+}
+
+func TestMediaUpload(t *testing.T) {
+	var (
+		s        = getTestServer()
+		ctx      = context.Background()
+		kurrik   = s.NewClient("kurrik")
+		uploader = twittergo.NewMediaUploader(kurrik)
+		data     = bytes.Repeat([]byte("x"), 2500)
+	)
+	defer s.Close()
+	uploader.ChunkSize = 1000
+	uploader.Category = "tweet_video"
+	uploader.PollInterval = time.Millisecond
+	media, err := uploader.Upload(ctx, bytes.NewReader(data), int64(len(data)), "video/mp4")
+	if err != nil {
+		t.Fatalf("Upload returned error: %v", err)
+	}
+	if media.ProcessingInfo().State() != "succeeded" {
+		t.Errorf("Expected processing to succeed, got %v", media)
+	}
+	tweet, err := kurrik.UpdateStatus(ctx, twittergo.UpdateStatusParams{MediaIds: []string{media.MediaIdStr()}})
+	if err != nil {
+		t.Fatalf("UpdateStatus returned error: %v", err)
+	}
+	if v1, _ := tweet.ToV1(); v1.Entities == nil || len(v1.Entities.Media) != 1 || v1.Entities.Media[0].Type != "video" {
+		t.Errorf("Expected attached video, got %v", tweet)
+	}
+	_, err = kurrik.UpdateStatus(ctx, twittergo.UpdateStatusParams{MediaIds: []string{"12345"}})
+	if !hasCode(err, ERROR_INVALID_MEDIA) {
+		t.Errorf("Expected invalid media error, got %v", err)
+	}
+}
+
+func TestRateLimits(t *testing.T) {
+	var (
+		s      = getTestServer()
+		ctx    = context.Background()
+		kurrik = s.NewClient("kurrik")
+		params = twittergo.ShowUserParams{ScreenName: "gopher"}
+	)
+	defer s.Close()
+	s.SetRateLimit(twittergo.PATH_SHOW_USER, 2)
+	for i := 0; i < 2; i++ {
+		if _, err := kurrik.ShowUser(ctx, params); err != nil {
+			t.Fatalf("ShowUser returned error: %v", err)
+		}
+	}
+	if left, ok := kurrik.RateLimitRemaining(twittergo.PATH_SHOW_USER); !ok || left != 0 {
+		t.Errorf("Expected no requests remaining, got %v %v", left, ok)
+	}
+	_, err := kurrik.ShowUser(ctx, params)
+	if limit, ok := err.(twittergo.RateLimitError); !ok || limit.Limit != 2 || limit.Reset.IsZero() {
+		t.Fatalf("Expected RateLimitError, got %v", err)
+	}
+	// Limits are counted separately for each user and for the app.
+	if _, err = s.NewClient("gopher").ShowUser(ctx, params); err != nil {
+		t.Errorf("Expected separate limit for another user, got %v", err)
+	}
+	if _, err = s.NewClient("").ShowUser(ctx, params); err != nil {
+		t.Errorf("Expected separate limit for app auth, got %v", err)
+	}
+	s.ResetRateLimits()
+	if _, err = kurrik.ShowUser(ctx, params); err != nil {
+		t.Errorf("Expected limit to reset, got %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var (
+		s      = getTestServer()
+		ctx    = context.Background()
+		kurrik = s.NewClient("kurrik")
+		params = twittergo.UserTimelineParams{ScreenName: "kurrik"}
+	)
+	defer s.Close()
+	s.InjectError(twittergo.PATH_USER_TIMELINE, http.StatusUnauthorized, APIError{twittergo.ERROR_INVALID_TOKEN, "Invalid or expired token."})
+	if _, err := kurrik.UserTimeline(ctx, params); !hasCode(err, twittergo.ERROR_INVALID_TOKEN) {
+		t.Errorf("Expected injected error, got %v", err)
+	}
+	if _, err := kurrik.UserTimeline(ctx, params); err != nil {
+		t.Errorf("Expected injected error to be used once, got %v", err)
+	}
+	if _, err := s.NewClient("").UpdateStatus(ctx, twittergo.UpdateStatusParams{Status: "hi"}); !hasCode(err, ERROR_CANNOT_ACCESS) {
+		t.Errorf("Expected app auth to be refused, got %v", err)
+	}
+	if err := call(t, kurrik, "GET", "/1.1/missing.json", url.Values{}, nil); !hasCode(err, ERROR_PAGE_NOT_FOUND) {
+		t.Errorf("Expected page not found error, got %v", err)
+	}
+}